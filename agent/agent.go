@@ -3,19 +3,32 @@ package agent
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/odpf/meteor/deadletter"
 	"github.com/odpf/meteor/models"
 	"github.com/odpf/meteor/plugins"
 	"github.com/odpf/meteor/recipe"
 	"github.com/odpf/meteor/registry"
+	"github.com/odpf/meteor/state"
 	"github.com/odpf/salt/log"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const defaultBatchSize = 1
 
+var (
+	errNoSource         = errors.New("recipe has no source")
+	errDeadLetterClosed = errors.New("dead letter is closed")
+)
+
 // TimerFn of function type
 type TimerFn func() func() int
 
@@ -24,18 +37,35 @@ type Agent struct {
 	extractorFactory *registry.ExtractorFactory
 	processorFactory *registry.ProcessorFactory
 	sinkFactory      *registry.SinkFactory
-	monitor          Monitor
+	stateStore       state.Store
+	deadLetter       deadletter.Writer
+	observer         observers
+	tracer           trace.Tracer
 	logger           log.Logger
 	retrier          *retrier
 	stopOnSinkError  bool
+	maxParallelism   int
+	drainTimeout     time.Duration
+	dryRun           bool
+	dryRunWriter     io.Writer
 	timerFn          TimerFn
 }
 
 // NewAgent returns an Agent with plugin factories.
 func NewAgent(config Config) *Agent {
-	mt := config.Monitor
-	if isNilMonitor(mt) {
-		mt = new(defaultMonitor)
+	var obs observers
+	for _, o := range config.Observers {
+		if !isNilObserver(o) {
+			obs = append(obs, o)
+		}
+	}
+	if !isNilMonitor(config.Monitor) {
+		obs = append(obs, monitorObserver{monitor: config.Monitor})
+	}
+
+	tracerProvider := config.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
 	}
 
 	timerFn := config.TimerFn
@@ -43,13 +73,31 @@ func NewAgent(config Config) *Agent {
 		timerFn = startDuration
 	}
 
+	stateStore := config.StateStore
+	if config.DryRun && stateStore != nil {
+		// dry runs read watermarks of previous runs without moving them
+		stateStore = state.ReadOnly(stateStore)
+	}
+
+	dryRunWriter := config.DryRunWriter
+	if dryRunWriter == nil {
+		dryRunWriter = os.Stdout
+	}
+
 	retrier := newRetrier(config.MaxRetries, config.RetryInitialInterval)
 	return &Agent{
 		extractorFactory: config.ExtractorFactory,
 		processorFactory: config.ProcessorFactory,
 		sinkFactory:      config.SinkFactory,
+		stateStore:       stateStore,
+		deadLetter:       config.DeadLetter,
 		stopOnSinkError:  config.StopOnSinkError,
-		monitor:          mt,
+		maxParallelism:   config.MaxParallelism,
+		drainTimeout:     config.DrainTimeout,
+		dryRun:           config.DryRun,
+		dryRunWriter:     dryRunWriter,
+		observer:         obs,
+		tracer:           tracerProvider.Tracer(tracerName),
 		logger:           config.Logger,
 		retrier:          retrier,
 		timerFn:          timerFn,
@@ -58,11 +106,18 @@ func NewAgent(config Config) *Agent {
 
 // Validate checks the recipe for linting errors.
 func (r *Agent) Validate(rcp recipe.Recipe) (errs []error) {
-	if ext, err := r.extractorFactory.Get(rcp.Source.Name); err != nil {
-		errs = append(errs, err)
-	} else {
-		if err = ext.Validate(rcp.Source.Config); err != nil {
-			errs = append(errs, r.enrichInvalidConfigError(err, rcp.Source.Name, plugins.PluginTypeExtractor))
+	sources := rcp.AllSources()
+	if len(sources) == 0 {
+		errs = append(errs, errNoSource)
+	}
+	for _, src := range sources {
+		ext, err := r.extractorFactory.Get(src.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = ext.Validate(src.Config); err != nil {
+			errs = append(errs, r.enrichInvalidConfigError(err, src.Name, plugins.PluginTypeExtractor))
 		}
 	}
 
@@ -91,20 +146,37 @@ func (r *Agent) Validate(rcp recipe.Recipe) (errs []error) {
 }
 
 // RunMultiple executes multiple recipes.
+// Recipes with a higher priority are started first and at most maxParallelism
+// recipes of the same group run at the same time, recipes without a group share one group.
 func (r *Agent) RunMultiple(ctx context.Context, recipes []recipe.Recipe) []Run {
 	var wg sync.WaitGroup
 	runs := make([]Run, len(recipes))
 
-	for i, recipe := range recipes {
-		wg.Add(1)
+	for _, queue := range queueRecipes(recipes) {
+		workers := r.maxParallelism
+		if workers == 0 || workers > len(queue) {
+			workers = len(queue)
+		}
 
-		tempIndex := i
-		tempRecipe := recipe
-		go func() {
-			run := r.Run(ctx, tempRecipe)
-			runs[tempIndex] = run
-			wg.Done()
-		}()
+		jobs := make(chan int, len(queue))
+		for _, i := range queue {
+			jobs <- i
+		}
+		close(jobs)
+
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					if ctx.Err() != nil {
+						runs[i] = Run{Recipe: recipes[i], Error: errors.Wrap(ctx.Err(), "recipe was not started")}
+						continue
+					}
+					runs[i] = r.Run(ctx, recipes[i])
+				}
+			}()
+		}
 	}
 
 	wg.Wait()
@@ -113,9 +185,18 @@ func (r *Agent) RunMultiple(ctx context.Context, recipes []recipe.Recipe) []Run
 }
 
 // Run executes the specified recipe.
+// The extractors of a recipe with several sources run concurrently into the same processors and sinks.
 func (r *Agent) Run(ctx context.Context, recipe recipe.Recipe) (run Run) {
 	run.Recipe = recipe
 	r.logger.Info("running recipe", "recipe", run.Recipe.Name)
+	// errors of plugins can hold values of their config, such as a connection url with its password
+	sensitive := recipe.SensitiveValues(r.schemas())
+	r.observer.OnRunStart(recipe)
+
+	ctx, span := r.startSpan(ctx, "meteor.run", attrRecipe.String(recipe.Name), attrPlugin.String(recipe.SourceNames()))
+	defer func() {
+		endSpan(span, run.Error)
+	}()
 
 	var (
 		getDuration = r.timerFn()
@@ -125,24 +206,100 @@ func (r *Agent) Run(ctx context.Context, recipe recipe.Recipe) (run Run) {
 
 	defer func() {
 		durationInMs := getDuration()
+		run.Error = maskError(run.Error, sensitive)
 		r.logAndRecordMetrics(run, durationInMs)
 	}()
 
-	runExtractor, err := r.setupExtractor(ctx, recipe.Source, stream)
+	parentCtx := ctx
+	if recipe.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, recipe.Timeout)
+		defer cancel()
+	}
+
+	sources := recipe.AllSources()
+	if len(sources) == 0 {
+		run.Error = errNoSource
+		return
+	}
+	// watermarks set by the extractors are only saved once the run succeeded and its records reached the sinks,
+	// so the assets of a failed run are extracted again on the next run
+	var watermarks *state.StagedStore
+	if r.stateStore != nil {
+		watermarks = state.Staged(r.stateStore)
+	}
+	// a source failing to setup is left out of the run, the run only stops when every source failed
+	sourceCounters := make([]*sourceCounter, len(sources))
+	runExtractors := make([]func() error, len(sources))
+	for i, sr := range sources {
+		counter := new(sourceCounter)
+		sourceCounters[i] = counter
+		runExtractor, err := r.setupExtractor(ctx, sr, func(rec models.Record) {
+			counter.add()
+			stream.push(rec)
+		}, recipe, watermarks)
+		if err != nil {
+			err = maskError(err, sensitive)
+			counter.fail(errors.Wrap(err, "failed to setup extractor"))
+			r.logger.Error("error setting up source", "recipe", recipe.Name, "source", sr.SourceID(), "error", err)
+			continue
+		}
+		runExtractors[i] = runExtractor
+	}
+	defer func() {
+		for i, sr := range sources {
+			src := sourceCounters[i].sourceRun(sr)
+			src.Error = maskError(src.Error, sensitive)
+			run.Sources = append(run.Sources, src)
+		}
+	}()
+	if run.Error = firstSourceError(sourceCounters); allSourcesFailed(sourceCounters) {
+		return
+	}
+
+	changes, err := r.setupChangeDetection(recipe)
+	if err != nil {
+		run.Error = errors.Wrap(err, "failed to setup change detection")
+		return
+	}
+
+	deletions, err := r.setupDeletionDetection(recipe)
+	if err != nil {
+		run.Error = errors.Wrap(err, "failed to setup deletion detection")
+		return
+	}
+
+	deadLetter, closeDeadLetter, err := r.setupDeadLetter(ctx, recipe)
 	if err != nil {
-		run.Error = errors.Wrap(err, "failed to setup extractor")
+		run.Error = errors.Wrap(err, "failed to setup dead letter")
 		return
 	}
+	// subscribers abandoned at the drain timeout can still be writing, their writes are dropped once it is closed
+	defer closeDeadLetter()
+
+	stream.setMiddleware(func(src models.Record) (models.Record, error) {
+		r.observer.OnRecordExtracted(recipe.Name, src)
+		return src, nil
+	})
+
+	// assets are tracked before processors, so the ones a processor skips are not considered deleted
+	if deletions != nil {
+		stream.setMiddleware(deletions.track)
+	}
 
 	for _, pr := range recipe.Processors {
-		if err := r.setupProcessor(ctx, pr, stream); err != nil {
+		closeDivert, err := r.setupProcessor(ctx, pr, stream, recipe, deadLetter)
+		if err != nil {
 			run.Error = errors.Wrap(err, "failed to setup processor")
 			return
 		}
+		defer closeDivert()
 	}
 
-	for _, sr := range recipe.Sinks {
-		err := r.setupSink(ctx, sr, stream, recipe)
+	sinkCounters := make([]*sinkCounter, len(recipe.Sinks))
+	for i, sr := range recipe.Sinks {
+		sinkCounters[i] = new(sinkCounter)
+		err := r.setupSink(ctx, i, sr, stream, recipe, deadLetter, sinkCounters[i])
 		if err != nil {
 			run.Error = errors.Wrap(err, "failed to setup sink")
 			return
@@ -156,54 +313,167 @@ func (r *Agent) Run(ctx context.Context, recipe recipe.Recipe) (run Run) {
 		return src, nil
 	})
 
-	// a goroutine to shut down stream gracefully
+	// records unchanged since the previous run are counted as extracted, but not sent to the sinks
+	if changes != nil {
+		stream.setMiddleware(changes.detect)
+	}
+
+	// a goroutine to shut down stream gracefully,
+	// giving sinks until the drain timeout to flush their remaining batches
+	broadcastDone := make(chan struct{})
+	defer close(broadcastDone)
 	go func() {
-		<-ctx.Done()
-		r.logger.Info("force closing run", "recipe", recipe.Name)
-		stream.Close()
+		select {
+		case <-ctx.Done():
+			r.logger.Info("force closing run", "recipe", recipe.Name)
+			stream.closeWithTimeout(r.drainTimeout)
+		case <-broadcastDone:
+		}
 	}()
 
-	// a goroutine to let extractor concurrently emit data
+	// goroutines to let extractors concurrently emit data
 	// while stream is listening via stream.Listen().
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				run.Error = fmt.Errorf("%s", r)
+	var extractorsWG sync.WaitGroup
+	for i, runExtractor := range runExtractors {
+		if runExtractor == nil {
+			continue
+		}
+		extractorsWG.Add(1)
+		go func(counter *sourceCounter, runExtractor func() error) {
+			defer extractorsWG.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					counter.fail(fmt.Errorf("%s", r))
+				}
+			}()
+			if err := runExtractor(); err != nil {
+				counter.fail(errors.Wrap(err, "failed to run extractor"))
 			}
-			stream.Close()
-		}()
-		err = runExtractor()
-		if err != nil {
-			run.Error = errors.Wrap(err, "failed to run extractor")
+		}(sourceCounters[i], runExtractor)
+	}
+	var deletedCount int64
+	go func() {
+		extractorsWG.Wait()
+		// a failed or cancelled run may have missed assets, so none are considered deleted
+		if deletions != nil && firstSourceError(sourceCounters) == nil && ctx.Err() == nil && !stream.isClosed() {
+			atomic.StoreInt64(&deletedCount, r.sendTombstones(stream, deletions, changes, recipe))
 		}
+		stream.Close()
 	}()
 
 	// start listening.
 	// this process is blocking
-	if err := stream.broadcast(); err != nil {
+	err = stream.broadcast()
+	run.Error = firstSourceError(sourceCounters)
+	if err != nil {
 		run.Error = errors.Wrap(err, "failed to broadcast stream")
 	}
+	// the recipe timeout is the cause of any error the run ended with
+	if ctx.Err() == context.DeadlineExceeded && parentCtx.Err() == nil {
+		run.Error = TimeoutError{Stage: TimeoutStageRecipe, Timeout: recipe.Timeout}
+	}
 
 	// code will reach here stream.Listen() is done.
 	run.RecordCount = recordCount
+	run.DroppedCount = stream.droppedCount()
+	run.SkippedCount = stream.skippedCount()
+	run.DeletedCount = int(atomic.LoadInt64(&deletedCount))
+	for i, sr := range recipe.Sinks {
+		run.Sinks = append(run.Sinks, sinkCounters[i].sinkRun(sr.Name))
+	}
 	success := run.Error == nil
 	run.Success = success
+	// a run goes on when sinks reject records unless STOP_ON_SINK_ERROR is set, its state is only saved
+	// once every record reached the sinks, so the records of the run are sent again on the next run
+	delivered := success && run.DroppedCount == 0 && run.SinkFailureCount() == 0
+	if changes != nil {
+		run.Changes = changes.changeRun()
+		if delivered {
+			if err := changes.save(); err != nil {
+				r.logger.Warn("error saving record hashes", "recipe", recipe.Name, "error", err)
+			}
+		}
+	}
+	// assets of the run are compared again on the next run, so tombstones the sinks rejected are sent again
+	if deletions != nil && delivered {
+		if err := deletions.save(); err != nil {
+			r.logger.Warn("error saving extracted assets", "recipe", recipe.Name, "error", err)
+		}
+	}
+	if watermarks != nil && delivered {
+		if err := watermarks.Commit(); err != nil {
+			r.logger.Warn("error saving state", "recipe", recipe.Name, "error", err)
+		}
+	}
+	return
+}
+
+// Replay sends the records of a dead letter entry again to the sink of the recipe they were rejected by.
+func (r *Agent) Replay(ctx context.Context, rcp recipe.Recipe, entry deadletter.Entry) (err error) {
+	if entry.Processor != "" {
+		return fmt.Errorf("records diverted by processor \"%s\" cannot be replayed to a sink", entry.Processor)
+	}
+
+	sr, ok := findSink(rcp, entry)
+	if !ok {
+		return fmt.Errorf("could not find sink \"%s\" at index %d in recipe \"%s\"", entry.Sink, entry.SinkIndex, rcp.Name)
+	}
+
+	var sink plugins.Syncer
+	if sink, err = r.sinkFactory.Get(sr.Name); err != nil {
+		return errors.Wrapf(err, "could not find sink \"%s\"", sr.Name)
+	}
+	if err = sink.Init(ctx, sr.Config); err != nil {
+		return errors.Wrapf(err, "could not initiate sink \"%s\"", sr.Name)
+	}
+	defer func() {
+		if err := sink.Close(); err != nil {
+			r.logger.Warn("error closing sink", "sink", sr.Name, "error", err)
+		}
+	}()
+
+	err = r.retrier.withPolicy(sr.Retry).retry(func() error {
+		return sink.Sink(ctx, entry.Records)
+	}, func(e error, d time.Duration) {
+		r.logger.Info(fmt.Sprintf("retrying sink in %d", d), "sink", sr.Name, "error", e.Error())
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error running sink \"%s\"", sr.Name)
+	}
+
 	return
 }
 
-func (r *Agent) setupExtractor(ctx context.Context, sr recipe.PluginRecipe, str *stream) (runFn func() error, err error) {
+func (r *Agent) setupExtractor(ctx context.Context, sr recipe.PluginRecipe, emit plugins.Emit, recipe recipe.Recipe, watermarks *state.StagedStore) (runFn func() error, err error) {
 	extractor, err := r.extractorFactory.Get(sr.Name)
 	if err != nil {
 		err = errors.Wrapf(err, "could not find extractor \"%s\"", sr.Name)
 		return
 	}
-	if err = extractor.Init(ctx, sr.Config); err != nil {
+	if se, ok := extractor.(plugins.StatefulExtractor); ok && watermarks != nil {
+		se.SetState(state.Scope(watermarks, stateNamespace(recipe.Name, sr.SourceID())))
+	}
+	if rle, ok := extractor.(plugins.RateLimitedExtractor); ok {
+		rle.SetRateLimiter(extractorRateLimiter(sr))
+	}
+	timeouts := pluginTimeouts(sr)
+	initCtx, span := r.startSpan(ctx, "extractor.init", attrPlugin.String(sr.Name))
+	err = withTimeout(initCtx, timeouts.Init, TimeoutStageInit, sr.Name, func(ctx context.Context) error {
+		return extractor.Init(ctx, sr.Config)
+	})
+	endSpan(span, err)
+	if err != nil {
 		err = errors.Wrapf(err, "could not initiate extractor \"%s\"", sr.Name)
 		return
 	}
 
 	runFn = func() (err error) {
-		if err = extractor.Extract(ctx, str.push); err != nil {
+		extractCtx, span := r.startSpan(ctx, "extractor.extract", attrPlugin.String(sr.Name))
+		err = withTimeout(extractCtx, timeouts.Run, TimeoutStageExtract, sr.Name, func(ctx context.Context) error {
+			return extractor.Extract(ctx, emit)
+		})
+		endSpan(span, err)
+		if err != nil {
 			err = errors.Wrapf(err, "error running extractor \"%s\"", sr.Name)
 		}
 		return
@@ -212,21 +482,118 @@ func (r *Agent) setupExtractor(ctx context.Context, sr recipe.PluginRecipe, str
 	return
 }
 
-func (r *Agent) setupProcessor(ctx context.Context, pr recipe.PluginRecipe, str *stream) (err error) {
+// setupChangeDetection returns the change detector of the recipe, or nil when change detection is not enabled.
+func (r *Agent) setupChangeDetection(rcp recipe.Recipe) (*changeDetector, error) {
+	if rcp.ChangeDetection == nil {
+		return nil, nil
+	}
+	if r.stateStore == nil {
+		return nil, errors.New("change detection needs a state store to keep the records of the previous run")
+	}
+
+	return newChangeDetector(state.Scope(r.stateStore, rcp.Name), rcp.ChangeDetection.IgnoreFields)
+}
+
+// setupDeletionDetection returns the deletion detector of the recipe, or nil when deletion detection is not enabled.
+func (r *Agent) setupDeletionDetection(rcp recipe.Recipe) (*deletionDetector, error) {
+	if rcp.DeletionDetection == nil {
+		return nil, nil
+	}
+	if r.stateStore == nil {
+		return nil, errors.New("deletion detection needs a state store to keep the assets of the previous run")
+	}
+	// incremental extractors only emit the assets changed since their previous run
+	for _, sr := range rcp.AllSources() {
+		extractor, err := r.extractorFactory.Get(sr.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find extractor \"%s\"", sr.Name)
+		}
+		if _, ok := extractor.(plugins.StatefulExtractor); ok {
+			return nil, fmt.Errorf("deletion detection cannot be used with incremental extractor \"%s\"", sr.Name)
+		}
+	}
+
+	return newDeletionDetector(state.Scope(r.stateStore, rcp.Name))
+}
+
+// sendTombstones sends a tombstone record for each asset not extracted anymore to the sinks,
+// and returns the number of tombstones sent.
+func (r *Agent) sendTombstones(str *stream, deletions *deletionDetector, changes *changeDetector, rcp recipe.Recipe) int64 {
+	tombstones, err := deletions.tombstones(time.Now())
+	if err != nil {
+		r.logger.Warn("error building tombstones", "recipe", rcp.Name, "error", err)
+		return 0
+	}
+
+	var urns []string
+	for _, record := range tombstones {
+		urn := record.Data().GetResource().GetUrn()
+		r.logger.Info("Asset not extracted anymore, sending tombstone", "record", urn, "recipe", rcp.Name)
+		str.pushUnprocessed(record)
+		urns = append(urns, urn)
+	}
+	if changes != nil {
+		changes.forget(urns)
+	}
+
+	return int64(len(tombstones))
+}
+
+// setupProcessor registers the processor as a stream middleware applying its error policy.
+// The returned close function closes the divert destination created for the processor.
+func (r *Agent) setupProcessor(ctx context.Context, pr recipe.PluginRecipe, str *stream, rcp recipe.Recipe, deadLetter deadletter.Writer) (closeFn func(), err error) {
+	closeFn = func() {}
+
 	var proc plugins.Processor
 	if proc, err = r.processorFactory.Get(pr.Name); err != nil {
-		return errors.Wrapf(err, "could not find processor \"%s\"", pr.Name)
+		return closeFn, errors.Wrapf(err, "could not find processor \"%s\"", pr.Name)
 	}
-	if err = proc.Init(ctx, pr.Config); err != nil {
-		return errors.Wrapf(err, "could not initiate processor \"%s\"", pr.Name)
+	initCtx, span := r.startSpan(ctx, "processor.init", attrPlugin.String(pr.Name))
+	err = withTimeout(initCtx, pluginTimeouts(pr).Init, TimeoutStageInit, pr.Name, func(ctx context.Context) error {
+		return proc.Init(ctx, pr.Config)
+	})
+	endSpan(span, err)
+	if err != nil {
+		return closeFn, errors.Wrapf(err, "could not initiate processor \"%s\"", pr.Name)
+	}
+
+	divert := deadLetter
+	if pr.OnError == recipe.ErrorPolicyDivert {
+		if pr.Divert != nil {
+			if divert, closeFn, err = r.openDeadLetter(ctx, rcp.Name, *pr.Divert); err != nil {
+				return closeFn, errors.Wrapf(err, "could not open divert of processor \"%s\"", pr.Name)
+			}
+		}
+		if divert == nil {
+			return closeFn, fmt.Errorf("processor \"%s\" diverts records but has no divert and there is no dead letter", pr.Name)
+		}
 	}
 
 	str.setMiddleware(func(src models.Record) (dst models.Record, err error) {
-		dst, err = proc.Process(ctx, src)
-		if err != nil {
-			err = errors.Wrapf(err, "error running processor \"%s\"", pr.Name)
+		processCtx, span := r.startSpan(ctx, "processor.process", attrPlugin.String(pr.Name), attrRecord.String(src.Data().GetResource().Urn))
+		dst, err = proc.Process(processCtx, src)
+		endSpan(span, err)
+		r.observer.OnRecordProcessed(rcp.Name, pr.Name, src, err)
+		if err == nil {
 			return
 		}
+		err = errors.Wrapf(err, "error running processor \"%s\"", pr.Name)
+
+		switch pr.OnError {
+		case recipe.ErrorPolicySkip:
+			r.logger.Warn("skipping record", "processor", pr.Name, "recipe", rcp.Name, "record", src.Data().GetResource().Urn, "error", err)
+			return models.Record{}, errSkipRecord
+		case recipe.ErrorPolicyDivert:
+			r.writeDeadLetter(ctx, divert, deadletter.Entry{
+				Timestamp: time.Now(),
+				Recipe:    rcp.Name,
+				Processor: pr.Name,
+				Error:     err.Error(),
+				Attempts:  1,
+				Records:   []models.Record{src},
+			})
+			return models.Record{}, errSkipRecord
+		}
 
 		return
 	})
@@ -234,38 +601,85 @@ func (r *Agent) setupProcessor(ctx context.Context, pr recipe.PluginRecipe, str
 	return
 }
 
-func (r *Agent) setupSink(ctx context.Context, sr recipe.PluginRecipe, stream *stream, recipe recipe.Recipe) (err error) {
+func (r *Agent) setupSink(ctx context.Context, index int, sr recipe.PluginRecipe, stream *stream, recipe recipe.Recipe, deadLetter deadletter.Writer, counter *sinkCounter) (err error) {
 	var sink plugins.Syncer
 
 	if sink, err = r.sinkFactory.Get(sr.Name); err != nil {
 		return errors.Wrapf(err, "could not find sink \"%s\"", sr.Name)
 	}
-	if err = sink.Init(ctx, sr.Config); err != nil {
+	if r.dryRun {
+		return r.setupDryRunSink(ctx, sr, sink, stream, recipe)
+	}
+	timeouts := pluginTimeouts(sr)
+	initCtx, span := r.startSpan(ctx, "sink.init", attrPlugin.String(sr.Name))
+	err = withTimeout(initCtx, timeouts.Init, TimeoutStageInit, sr.Name, func(ctx context.Context) error {
+		return sink.Init(ctx, sr.Config)
+	})
+	endSpan(span, err)
+	if err != nil {
 		return errors.Wrapf(err, "could not initiate sink \"%s\"", sr.Name)
 	}
+	retrier := r.retrier.withPolicy(sr.Retry)
+	sensitive := recipe.SensitiveValues(r.schemas())
 	retryNotification := func(e error, d time.Duration) {
 		r.logger.Info(
 			fmt.Sprintf("retrying sink in %d", d),
 			"sink", sr.Name,
-			"error", e.Error())
+			"error", maskError(e, sensitive).Error())
 	}
-	stream.subscribe(func(records []models.Record) error {
-		err := r.retrier.retry(func() error {
-			err := sink.Sink(ctx, records)
+	// a sink call still running after its timeout is waited for, until the drain timeout,
+	// before the sink is called again or closed
+	var calls inFlight
+	sub := stream.subscribe(func(records []models.Record) error {
+		var attempts int
+		start := time.Now()
+		batchCtx, span := r.startSpan(ctx, "sink.batch", attrPlugin.String(sr.Name), attrRecordCount.Int(len(records)))
+		err := retrier.retry(func() error {
+			attempts++
+			if !calls.wait(r.drainTimeout) {
+				return errors.Errorf("sink \"%s\" is still running a call that timed out", sr.Name)
+			}
+			attemptCtx, attemptSpan := r.startSpan(batchCtx, "sink.attempt", attrPlugin.String(sr.Name), attrAttempt.Int(attempts))
+			running, err := runWithTimeout(attemptCtx, timeouts.Run, TimeoutStageSink, sr.Name, func(ctx context.Context) error {
+				return sink.Sink(ctx, records)
+			})
+			calls.track(running)
+			if errors.Is(err, TimeoutError{}) {
+				counter.timeout()
+			}
+			endSpan(attemptSpan, err)
 			return err
 		}, retryNotification)
+		err = maskError(err, sensitive)
+		endSpan(span, err)
 
 		var success bool
 		if err != nil {
 			// once it reaches here, it means that the retry has been exhausted and still got error
 			success = false
 			r.logger.Error("error running sink", "sink", sr.Name, "error", err.Error())
+			r.writeDeadLetter(ctx, deadLetter, deadletter.Entry{
+				Timestamp: time.Now(),
+				Recipe:    recipe.Name,
+				Sink:      sr.Name,
+				SinkIndex: index,
+				Error:     err.Error(),
+				Attempts:  attempts,
+				Records:   records,
+			})
 		} else {
 			success = true
 			r.logger.Info("Successfully published record", "sink", sr.Name, "recipe", recipe.Name)
 		}
 
-		r.monitor.RecordPlugin(recipe.Name, sr.Name, "sink", success)
+		r.observer.OnSinkBatch(recipe.Name, SinkBatch{
+			Sink:        sr.Name,
+			RecordCount: len(records),
+			Attempts:    attempts,
+			Latency:     time.Since(start),
+			Err:         err,
+		})
+		counter.add(success, len(records))
 
 		if !r.stopOnSinkError {
 			err = nil
@@ -273,12 +687,56 @@ func (r *Agent) setupSink(ctx context.Context, sr recipe.PluginRecipe, stream *s
 		// TODO: create a new error to signal stopping stream.
 		// returning nil so stream wont stop.
 		return err
-	}, defaultBatchSize)
+	}, sinkBatchOptions(sr), sinkBufferOptions(sr, &counter.buffer))
+
+	// stream runs onClose callbacks once the subscriber has flushed its last batch,
+	// and never while the sink is still sending a batch
+	sub.onClose(func() {
+		closeSink := func() {
+			if err := sink.Close(); err != nil {
+				r.logger.Warn("error closing sink", "sink", sr.Name, "error", err)
+			}
+		}
+		if calls.wait(r.drainTimeout) {
+			closeSink()
+			return
+		}
+		r.logger.Warn("sink is still running a call that timed out, closing it once the call returns", "sink", sr.Name)
+		go func() {
+			calls.wait(0)
+			closeSink()
+		}()
+	})
 
-	//TODO: the sink closes even though some records remain unpublished
-	//TODO: once fixed, file sink's Close needs to close *File
-	stream.onClose(func() {
-		if err = sink.Close(); err != nil {
+	return
+}
+
+// setupDryRunSink subscribes a recorder printing what the sink would receive in place of the sink.
+// Only sinks able to preview their payloads are initiated.
+func (r *Agent) setupDryRunSink(ctx context.Context, sr recipe.PluginRecipe, sink plugins.Syncer, stream *stream, rcp recipe.Recipe) (err error) {
+	if err = sink.Validate(sr.Config); err != nil {
+		return r.enrichInvalidConfigError(err, sr.Name, plugins.PluginTypeSink)
+	}
+
+	previewer, ok := sink.(plugins.Previewer)
+	if ok {
+		err = withTimeout(ctx, pluginTimeouts(sr).Init, TimeoutStageInit, sr.Name, func(ctx context.Context) error {
+			return previewer.Init(ctx, sr.Config)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "could not initiate sink \"%s\"", sr.Name)
+		}
+	}
+
+	recorder := newDryRunRecorder(rcp.Name, sr.Name, previewer, rcp.SensitiveValues(r.schemas()))
+	stream.subscribe(func(records []models.Record) error {
+		return recorder.record(ctx, records)
+	}, sinkBatchOptions(sr), sinkBufferOptions(sr, nil)).onClose(func() {
+		recorder.print(r.dryRunWriter)
+		if previewer == nil {
+			return
+		}
+		if err := previewer.Close(); err != nil {
 			r.logger.Warn("error closing sink", "sink", sr.Name, "error", err)
 		}
 	})
@@ -286,14 +744,164 @@ func (r *Agent) setupSink(ctx context.Context, sr recipe.PluginRecipe, stream *s
 	return
 }
 
+// setupDeadLetter returns the dead letter of the recipe, falling back to the agent's dead letter.
+// The returned close function only closes a dead letter created for the recipe.
+func (r *Agent) setupDeadLetter(ctx context.Context, rcp recipe.Recipe) (w deadletter.Writer, closeFn func(), err error) {
+	if rcp.DeadLetter == nil {
+		return r.deadLetter, func() {}, nil
+	}
+
+	return r.openDeadLetter(ctx, rcp.Name, *rcp.DeadLetter)
+}
+
+// openDeadLetter creates a writer for the file or sink of the dead letter, along with its close function.
+func (r *Agent) openDeadLetter(ctx context.Context, recipeName string, dl recipe.DeadLetter) (w deadletter.Writer, closeFn func(), err error) {
+	closeFn = func() {}
+	if r.dryRun {
+		return deadletter.Discard, closeFn, nil
+	}
+	if dl.Path != "" {
+		if w, err = deadletter.NewFileWriter(dl.Path); err != nil {
+			return
+		}
+	} else {
+		sr := dl.Sink
+		var sink plugins.Syncer
+		if sink, err = r.sinkFactory.Get(sr.Name); err != nil {
+			err = errors.Wrapf(err, "could not find sink \"%s\"", sr.Name)
+			return
+		}
+		if err = sink.Init(ctx, sr.Config); err != nil {
+			err = errors.Wrapf(err, "could not initiate sink \"%s\"", sr.Name)
+			return
+		}
+		w = deadletter.NewSinkWriter(sink)
+	}
+
+	w = &closableDeadLetter{Writer: w}
+	closeFn = func() {
+		if err := w.Close(); err != nil {
+			r.logger.Warn("error closing dead letter", "recipe", recipeName, "error", err)
+		}
+	}
+	return
+}
+
+// closableDeadLetter rejects the writes coming once it is closed, such as the ones of sinks still sending
+// a batch when the run is abandoned at the drain timeout. Their records are already counted as dropped.
+type closableDeadLetter struct {
+	deadletter.Writer
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func (w *closableDeadLetter) Write(ctx context.Context, entry deadletter.Entry) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return errDeadLetterClosed
+	}
+
+	return w.Writer.Write(ctx, entry)
+}
+
+func (w *closableDeadLetter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	return w.Writer.Close()
+}
+
+// writeDeadLetter sends the entry to the dead letter if there is one
+func (r *Agent) writeDeadLetter(ctx context.Context, w deadletter.Writer, entry deadletter.Entry) {
+	if w == nil {
+		return
+	}
+
+	err := w.Write(ctx, entry)
+	if errors.Is(err, errDeadLetterClosed) {
+		r.logger.Warn("dead letter closed, records dropped", "recipe", entry.Recipe, "sink", entry.Sink, "processor", entry.Processor, "records", len(entry.Records))
+		return
+	}
+	if err != nil {
+		r.logger.Error("error writing dead letter", "recipe", entry.Recipe, "sink", entry.Sink, "records", len(entry.Records), "error", err)
+		return
+	}
+	r.logger.Info("records sent to dead letter", "recipe", entry.Recipe, "sink", entry.Sink, "processor", entry.Processor, "records", len(entry.Records))
+}
+
+// pluginTimeouts returns the timeouts of a plugin, plugins without timeouts config have no timeout.
+func pluginTimeouts(pr recipe.PluginRecipe) recipe.Timeouts {
+	if pr.Timeouts == nil {
+		return recipe.Timeouts{}
+	}
+
+	return *pr.Timeouts
+}
+
+// extractorRateLimiter returns the rate limiter of an extractor, extractors without rate limit config
+// are not limited but still wait when the remote API responds with Retry-After.
+func extractorRateLimiter(sr recipe.PluginRecipe) *plugins.RateLimiter {
+	if sr.RateLimit == nil {
+		return plugins.NewRateLimiter(0, 0, 0)
+	}
+
+	return plugins.NewRateLimiter(sr.RateLimit.RequestsPerSecond, sr.RateLimit.Burst, sr.RateLimit.MaxInFlight)
+}
+
+// sinkBufferOptions returns the buffer options of a sink counting its use in stats,
+// sinks without buffer config receive records one at a time.
+func sinkBufferOptions(sr recipe.PluginRecipe, stats *bufferStats) bufferOptions {
+	if sr.Buffer == nil {
+		return bufferOptions{stats: stats}
+	}
+
+	return bufferOptions{
+		size:     sr.Buffer.Size,
+		spill:    sr.Buffer.OnFull == recipe.BufferPolicySpill,
+		spillDir: sr.Buffer.SpillDir,
+		stats:    stats,
+	}
+}
+
+// sinkBatchOptions returns the batch options of a sink, sinks without batch config receive one record per batch.
+func sinkBatchOptions(sr recipe.PluginRecipe) batchOptions {
+	if sr.Batch == nil {
+		return batchOptions{size: defaultBatchSize}
+	}
+
+	return batchOptions{
+		size:          sr.Batch.Size,
+		maxBytes:      sr.Batch.MaxBytes,
+		flushInterval: sr.Batch.FlushInterval,
+	}
+}
+
+// schemas returns the config schemas of the plugins of the agent, telling the sensitive config values of recipes.
+func (r *Agent) schemas() recipe.Schemas {
+	return recipe.Schemas{
+		Extractor: r.extractorFactory.Schema,
+		Processor: r.processorFactory.Schema,
+		Sink:      r.sinkFactory.Schema,
+	}
+}
+
 func (r *Agent) logAndRecordMetrics(run Run, durationInMs int) {
 	run.DurationInMs = durationInMs
-	r.monitor.RecordRun(run)
+	r.observer.OnRunEnd(run)
 	if run.Success {
 		r.logger.Info("done running recipe", "recipe", run.Recipe.Name, "duration_ms", durationInMs, "record_count", run.RecordCount)
 	} else {
 		r.logger.Error("error running recipe", "recipe", run.Recipe.Name, "duration_ms", durationInMs, "records_count", run.RecordCount, "err", run.Error)
 	}
+	if run.DroppedCount > 0 {
+		r.logger.Warn("records dropped while closing run", "recipe", run.Recipe.Name, "dropped_count", run.DroppedCount)
+	}
 }
 
 // enrichInvalidConfigError enrich the error with plugin information
@@ -309,6 +917,49 @@ func (r *Agent) enrichInvalidConfigError(err error, pluginName string, pluginTyp
 	return err
 }
 
+// findSink returns the sink of the recipe a dead letter entry was rejected by,
+// it is the sink at the index of the entry as long as the sink there has the same name.
+func findSink(rcp recipe.Recipe, entry deadletter.Entry) (recipe.PluginRecipe, bool) {
+	if entry.SinkIndex < 0 || entry.SinkIndex >= len(rcp.Sinks) {
+		return recipe.PluginRecipe{}, false
+	}
+	sr := rcp.Sinks[entry.SinkIndex]
+	if sr.Name != entry.Sink {
+		return recipe.PluginRecipe{}, false
+	}
+
+	return sr, true
+}
+
+// queueRecipes returns the indexes of the recipes per group, each ordered by priority.
+func queueRecipes(recipes []recipe.Recipe) (queues [][]int) {
+	order := make([]int, len(recipes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return recipes[order[a]].Priority > recipes[order[b]].Priority
+	})
+
+	groups := make(map[string]int)
+	for _, i := range order {
+		q, ok := groups[recipes[i].Group]
+		if !ok {
+			q = len(queues)
+			groups[recipes[i].Group] = q
+			queues = append(queues, nil)
+		}
+		queues[q] = append(queues[q], i)
+	}
+
+	return
+}
+
+// stateNamespace returns the state namespace of a plugin in a recipe.
+func stateNamespace(recipeName, pluginName string) string {
+	return fmt.Sprintf("%s/%s", recipeName, pluginName)
+}
+
 // startDuration starts a timer.
 func startDuration() func() int {
 	start := time.Now()
@@ -4,18 +4,22 @@ import (
 	"errors"
 
 	"github.com/odpf/meteor/models"
+	"google.golang.org/protobuf/proto"
 )
 
 // batch contains the configuration for a batch
 type batch struct {
 	data     []models.Record
 	capacity int
+	maxBytes int
+	bytes    int
 }
 
 // newBatch returns a new batch
-func newBatch(capacity int) *batch {
+func newBatch(capacity, maxBytes int) *batch {
 	return &batch{
 		capacity: capacity,
+		maxBytes: maxBytes,
 	}
 }
 
@@ -26,6 +30,7 @@ func (b *batch) add(d models.Record) error {
 	}
 
 	b.data = append(b.data, d)
+	b.bytes += recordSize(d)
 	return nil
 }
 
@@ -33,12 +38,17 @@ func (b *batch) add(d models.Record) error {
 func (b *batch) flush() []models.Record {
 	data := b.data
 	b.data = []models.Record{}
+	b.bytes = 0
 
 	return data
 }
 
 // isFull returns true if the batch is full
 func (b *batch) isFull() bool {
+	if b.maxBytes > 0 && b.bytes >= b.maxBytes {
+		return true
+	}
+
 	// size 0 means there is no limit, hence will not ever be full
 	if b.capacity == 0 {
 		return false
@@ -47,7 +57,27 @@ func (b *batch) isFull() bool {
 	return len(b.data) >= b.capacity
 }
 
+// fits returns true if the record can be added without going over the max bytes of the batch.
+// An empty batch always fits a record so a single large record is still sent.
+func (b *batch) fits(d models.Record) bool {
+	if b.maxBytes == 0 || b.isEmpty() {
+		return true
+	}
+
+	return b.bytes+recordSize(d) <= b.maxBytes
+}
+
 // isEmpty returns true if the batch is empty
 func (b *batch) isEmpty() bool {
 	return len(b.data) == 0
 }
+
+// recordSize returns the encoded size of the record data
+func recordSize(d models.Record) int {
+	msg, ok := d.Data().(proto.Message)
+	if !ok {
+		return 0
+	}
+
+	return proto.Size(msg)
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/odpf/meteor/models"
+	"github.com/odpf/meteor/plugins"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// stateKeyRecordHashes is the state key of the content hash of each record sent by the previous runs of a recipe
+const stateKeyRecordHashes = "record_hashes"
+
+// errUnchangedRecord is returned by the change detection middleware to drop a record unchanged since the previous run.
+var errUnchangedRecord = errors.New("record unchanged")
+
+// ChangeRun contains the number of records of a run that are new, changed or unchanged since the previous run.
+type ChangeRun struct {
+	NewCount       int `json:"new_count"`
+	ChangedCount   int `json:"changed_count"`
+	UnchangedCount int `json:"unchanged_count"`
+}
+
+// changeDetector compares the content hash of each record to the one of the previous run with the same URN.
+type changeDetector struct {
+	state        plugins.State
+	ignoreFields [][]string
+
+	mu       sync.Mutex
+	previous map[string]string
+	hashes   map[string]string
+	counts   ChangeRun
+}
+
+// newChangeDetector returns a detector comparing records to the hashes kept in state by the previous runs.
+func newChangeDetector(state plugins.State, ignoreFields []string) (*changeDetector, error) {
+	d := &changeDetector{
+		state:    state,
+		previous: make(map[string]string),
+		hashes:   make(map[string]string),
+	}
+	for _, field := range ignoreFields {
+		d.ignoreFields = append(d.ignoreFields, strings.Split(field, "."))
+	}
+
+	value, exists, err := state.Get(stateKeyRecordHashes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read record hashes")
+	}
+	if exists {
+		if err := json.Unmarshal([]byte(value), &d.previous); err != nil {
+			return nil, errors.Wrap(err, "failed to parse record hashes")
+		}
+	}
+
+	return d, nil
+}
+
+// detect is a stream middleware dropping records with the same content as in the previous run.
+// Records without URN cannot be compared, so they are always sent.
+func (d *changeDetector) detect(src models.Record) (models.Record, error) {
+	urn := src.Data().GetResource().GetUrn()
+	hash, err := d.hash(src)
+	if err != nil {
+		return src, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	previous, exists := d.previous[urn]
+	switch {
+	case urn == "" || !exists:
+		d.counts.NewCount++
+	case previous != hash:
+		d.counts.ChangedCount++
+	default:
+		d.counts.UnchangedCount++
+		d.hashes[urn] = hash
+		return src, errUnchangedRecord
+	}
+	if urn != "" {
+		d.hashes[urn] = hash
+	}
+
+	return src, nil
+}
+
+// hash returns the hash of the deterministic encoding of the record, without its ignored fields.
+func (d *changeDetector) hash(src models.Record) (string, error) {
+	msg, ok := src.Data().(proto.Message)
+	if !ok {
+		return "", errors.Errorf("record of type %T is not a protobuf message", src.Data())
+	}
+	msg = proto.Clone(msg)
+	for _, path := range d.ignoreFields {
+		clearField(msg.ProtoReflect(), path)
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode record")
+	}
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// save keeps the hashes of the records of the run for the next run,
+// along with the ones of records not extracted this time, such as by incremental extractors.
+func (d *changeDetector) save() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	hashes := make(map[string]string, len(d.previous)+len(d.hashes))
+	for urn, hash := range d.previous {
+		hashes[urn] = hash
+	}
+	for urn, hash := range d.hashes {
+		hashes[urn] = hash
+	}
+	value, err := json.Marshal(hashes)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode record hashes")
+	}
+
+	return d.state.Set(stateKeyRecordHashes, string(value))
+}
+
+// forget removes the hashes of the records with the given URNs, such as deleted assets,
+// so they are sent again if they are extracted on a next run.
+func (d *changeDetector) forget(urns []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, urn := range urns {
+		delete(d.previous, urn)
+		delete(d.hashes, urn)
+	}
+}
+
+// changeRun returns the counts of the records compared so far.
+func (d *changeDetector) changeRun() *ChangeRun {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	counts := d.counts
+	return &counts
+}
+
+// clearField clears the field at path in msg, paths not matching a field of msg are ignored.
+func clearField(msg protoreflect.Message, path []string) {
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if fd == nil || !msg.Has(fd) {
+		return
+	}
+	if len(path) == 1 {
+		msg.Clear(fd)
+		return
+	}
+	if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+		return
+	}
+
+	clearField(msg.Mutable(fd).Message(), path[1:])
+}
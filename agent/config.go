@@ -1,20 +1,32 @@
 package agent
 
 import (
+	"io"
 	"time"
 
+	"github.com/odpf/meteor/deadletter"
 	"github.com/odpf/meteor/registry"
+	"github.com/odpf/meteor/state"
 	"github.com/odpf/salt/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Config struct {
 	ExtractorFactory     *registry.ExtractorFactory
 	ProcessorFactory     *registry.ProcessorFactory
 	SinkFactory          *registry.SinkFactory
+	StateStore           state.Store
+	DeadLetter           deadletter.Writer
 	Monitor              Monitor
+	Observers            []Observer
+	TracerProvider       trace.TracerProvider
 	Logger               log.Logger
 	MaxRetries           int
 	RetryInitialInterval time.Duration
 	StopOnSinkError      bool
+	MaxParallelism       int
+	DrainTimeout         time.Duration
+	DryRun               bool
+	DryRunWriter         io.Writer
 	TimerFn              TimerFn
 }
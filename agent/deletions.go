@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/odpf/meteor/models"
+	commonv1beta1 "github.com/odpf/meteor/models/odpf/assets/common/v1beta1"
+	_ "github.com/odpf/meteor/models/odpf/assets/v1beta1" // registers the asset messages tombstones are built from
+	"github.com/odpf/meteor/plugins"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// stateKeyExtractedAssets is the state key of the assets extracted by the previous run of a recipe
+const stateKeyExtractedAssets = "extracted_assets"
+
+// extractedAsset is what is kept of an extracted asset to send its tombstone once it is not extracted anymore.
+type extractedAsset struct {
+	// Message is the full name of the protobuf message of the asset, such as odpf.assets.v1beta1.Table
+	Message string `json:"message"`
+	Name    string `json:"name,omitempty"`
+	Service string `json:"service,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// deletionDetector finds the assets extracted by the previous run of a recipe but not by the current one.
+type deletionDetector struct {
+	state plugins.State
+
+	mu        sync.Mutex
+	previous  map[string]extractedAsset
+	extracted map[string]extractedAsset
+}
+
+// newDeletionDetector returns a detector comparing the extracted assets to the ones kept in state by the previous run.
+func newDeletionDetector(state plugins.State) (*deletionDetector, error) {
+	d := &deletionDetector{
+		state:     state,
+		previous:  make(map[string]extractedAsset),
+		extracted: make(map[string]extractedAsset),
+	}
+
+	value, exists, err := state.Get(stateKeyExtractedAssets)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read extracted assets")
+	}
+	if exists {
+		if err := json.Unmarshal([]byte(value), &d.previous); err != nil {
+			return nil, errors.Wrap(err, "failed to parse extracted assets")
+		}
+	}
+
+	return d, nil
+}
+
+// track is a stream middleware noting the asset of each extracted record, records without URN are not tracked.
+func (d *deletionDetector) track(src models.Record) (models.Record, error) {
+	resource := src.Data().GetResource()
+	if resource.GetUrn() == "" {
+		return src, nil
+	}
+	msg, ok := src.Data().(proto.Message)
+	if !ok {
+		return src, errors.Errorf("record of type %T is not a protobuf message", src.Data())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.extracted[resource.GetUrn()] = extractedAsset{
+		Message: string(msg.ProtoReflect().Descriptor().FullName()),
+		Name:    resource.GetName(),
+		Service: resource.GetService(),
+		Type:    resource.GetType(),
+	}
+
+	return src, nil
+}
+
+// tombstones returns a tombstone record for each asset of the previous run not extracted by this run, ordered by URN.
+func (d *deletionDetector) tombstones(deletedAt time.Time) (records []models.Record, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var urns []string
+	for urn := range d.previous {
+		if _, ok := d.extracted[urn]; !ok {
+			urns = append(urns, urn)
+		}
+	}
+	sort.Strings(urns)
+
+	for _, urn := range urns {
+		record, err := newTombstone(urn, d.previous[urn], deletedAt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to build tombstone of \"%s\"", urn)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// save keeps the assets extracted by the run for the next run, deleted assets are forgotten.
+func (d *deletionDetector) save() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	value, err := json.Marshal(d.extracted)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode extracted assets")
+	}
+
+	return d.state.Set(stateKeyExtractedAssets, string(value))
+}
+
+// newTombstone returns a record of the asset type with only its resource and a delete event.
+func newTombstone(urn string, asset extractedAsset, deletedAt time.Time) (models.Record, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(asset.Message))
+	if err != nil {
+		return models.Record{}, err
+	}
+	msg := mt.New()
+	fields := msg.Descriptor().Fields()
+	resourceField, eventField := fields.ByName("resource"), fields.ByName("event")
+	if resourceField == nil || eventField == nil {
+		return models.Record{}, errors.Errorf("asset %s has no resource or event", asset.Message)
+	}
+
+	msg.Set(resourceField, protoreflect.ValueOfMessage((&commonv1beta1.Resource{
+		Urn:     urn,
+		Name:    asset.Name,
+		Service: asset.Service,
+		Type:    asset.Type,
+	}).ProtoReflect()))
+	msg.Set(eventField, protoreflect.ValueOfMessage((&commonv1beta1.Event{
+		Timestamp:   timestamppb.New(deletedAt),
+		Action:      models.EventActionDelete,
+		Description: "asset is not extracted anymore",
+	}).ProtoReflect()))
+
+	metadata, ok := msg.Interface().(models.Metadata)
+	if !ok {
+		return models.Record{}, errors.Errorf("asset %s is not metadata", asset.Message)
+	}
+
+	return models.NewRecord(metadata), nil
+}
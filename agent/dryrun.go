@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/odpf/meteor/models"
+	"github.com/odpf/meteor/plugins"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// dryRunSampleSize is the max number of urns and payloads printed per sink on a dry run
+const dryRunSampleSize = 5
+
+// dryRunRecorder records what a sink would receive on a dry run instead of sending it.
+type dryRunRecorder struct {
+	recipe    string
+	sink      string
+	previewer plugins.Previewer
+	sensitive []string
+	count     int
+	types     map[string]int
+	urns      []string
+	payloads  []string
+}
+
+// newDryRunRecorder returns a recorder for the sink, payloads are only rendered when previewer is not nil.
+// The sensitive values of the recipe are masked in the payloads, as they can carry the config of the sink.
+func newDryRunRecorder(recipeName, sinkName string, previewer plugins.Previewer, sensitive []string) *dryRunRecorder {
+	return &dryRunRecorder{
+		recipe:    recipeName,
+		sink:      sinkName,
+		previewer: previewer,
+		sensitive: sensitive,
+		types:     make(map[string]int),
+	}
+}
+
+// record counts the records of the batch and keeps samples of their urns and payloads.
+func (d *dryRunRecorder) record(ctx context.Context, batch []models.Record) error {
+	for _, record := range batch {
+		d.count++
+		d.types[assetType(record)]++
+		if len(d.urns) < dryRunSampleSize {
+			d.urns = append(d.urns, record.Data().GetResource().GetUrn())
+		}
+	}
+
+	if d.previewer == nil || len(d.payloads) >= dryRunSampleSize {
+		return nil
+	}
+	payloads, err := d.previewer.Preview(ctx, batch)
+	if err != nil {
+		return errors.Wrapf(err, "error rendering payloads of sink \"%s\"", d.sink)
+	}
+	for _, p := range payloads {
+		if len(d.payloads) >= dryRunSampleSize {
+			break
+		}
+		d.payloads = append(d.payloads, maskPayload(p, d.sensitive))
+	}
+
+	return nil
+}
+
+// print writes what the sink would have received.
+func (d *dryRunRecorder) print(w io.Writer) {
+	fmt.Fprintf(w, "[dry run] recipe \"%s\": sink \"%s\" would receive %d records\n", d.recipe, d.sink, d.count)
+
+	types := make([]string, 0, len(d.types))
+	for t := range d.types {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		fmt.Fprintf(w, "  %s: %d\n", t, d.types[t])
+	}
+
+	if len(d.urns) > 0 {
+		fmt.Fprintln(w, "  sample urns:")
+		for _, urn := range d.urns {
+			fmt.Fprintf(w, "    - %s\n", urn)
+		}
+	}
+
+	if len(d.payloads) > 0 {
+		fmt.Fprintln(w, "  sample payloads:")
+		for _, p := range d.payloads {
+			fmt.Fprintf(w, "    %s\n", strings.ReplaceAll(p, "\n", "\n    "))
+		}
+	}
+}
+
+// maskPayload returns the payload with the sensitive values and the passwords of the urls of each line masked.
+func maskPayload(payload string, sensitive []string) string {
+	lines := strings.Split(plugins.MaskString(payload, sensitive), "\n")
+	for i, line := range lines {
+		lines[i] = plugins.MaskURL(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// assetType returns the type of the record's resource, falling back to the name of its message.
+func assetType(record models.Record) string {
+	if t := record.Data().GetResource().GetType(); t != "" {
+		return t
+	}
+	if msg, ok := record.Data().(proto.Message); ok {
+		return string(proto.MessageName(msg))
+	}
+
+	return fmt.Sprintf("%T", record.Data())
+}
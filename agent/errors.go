@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeoutStage is the part of a run a timeout applies to
+type TimeoutStage string
+
+const (
+	// TimeoutStageRecipe is the stage of the whole recipe run
+	TimeoutStageRecipe TimeoutStage = "recipe"
+	// TimeoutStageInit is the stage of initiating a plugin
+	TimeoutStageInit TimeoutStage = "init"
+	// TimeoutStageExtract is the stage of running the extractor
+	TimeoutStageExtract TimeoutStage = "extract"
+	// TimeoutStageSink is the stage of a single sink call
+	TimeoutStageSink TimeoutStage = "sink"
+)
+
+// TimeoutError is returned when a recipe or one of its plugins did not finish within its timeout.
+type TimeoutError struct {
+	Stage      TimeoutStage
+	PluginName string
+	Timeout    time.Duration
+}
+
+func (e TimeoutError) Error() string {
+	if e.PluginName == "" {
+		return fmt.Sprintf("%s timed out after %s", e.Stage, e.Timeout)
+	}
+	return fmt.Sprintf("%s of \"%s\" timed out after %s", e.Stage, e.PluginName, e.Timeout)
+}
+
+func (e TimeoutError) Is(target error) bool {
+	_, ok := target.(TimeoutError)
+	return ok
+}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/odpf/meteor/models"
+	"github.com/odpf/meteor/recipe"
+)
+
+// Observer receives the events of the recipes run by the agent.
+// Events are sent from the goroutines running the recipes, so observers have to be safe for concurrent use
+// and should return quickly as they block the run. Embed BaseObserver to only implement some of the events.
+type Observer interface {
+	// OnRunStart is called before the plugins of the recipe are initiated.
+	OnRunStart(rcp recipe.Recipe)
+	// OnRecordExtracted is called for each record emitted by the extractor.
+	OnRecordExtracted(recipeName string, record models.Record)
+	// OnRecordProcessed is called for each record a processor ran on, err is the error of the processor if any.
+	OnRecordProcessed(recipeName, processorName string, record models.Record, err error)
+	// OnSinkBatch is called once a sink is done with a batch, after all retries.
+	OnSinkBatch(recipeName string, batch SinkBatch)
+	// OnRunEnd is called with the result of the run.
+	OnRunEnd(run Run)
+}
+
+// SinkBatch describes a batch sent to a sink.
+type SinkBatch struct {
+	Sink        string
+	RecordCount int
+	Attempts    int
+	// Latency is the time taken to send the batch, including retries.
+	Latency time.Duration
+	// Err is the error of the last attempt, nil when the batch was sent.
+	Err error
+}
+
+// BaseObserver ignores every event, it is meant to be embedded in observers.
+type BaseObserver struct{}
+
+func (BaseObserver) OnRunStart(rcp recipe.Recipe) {}
+
+func (BaseObserver) OnRecordExtracted(recipeName string, record models.Record) {}
+
+func (BaseObserver) OnRecordProcessed(recipeName, processorName string, record models.Record, err error) {
+}
+
+func (BaseObserver) OnSinkBatch(recipeName string, batch SinkBatch) {}
+
+func (BaseObserver) OnRunEnd(run Run) {}
+
+// monitorObserver sends the events of a Monitor to it.
+type monitorObserver struct {
+	BaseObserver
+	monitor Monitor
+}
+
+func (o monitorObserver) OnSinkBatch(recipeName string, batch SinkBatch) {
+	o.monitor.RecordPlugin(recipeName, batch.Sink, "sink", batch.Err == nil)
+}
+
+func (o monitorObserver) OnRunEnd(run Run) {
+	o.monitor.RecordRun(run)
+}
+
+// observers sends each event to all of its observers.
+type observers []Observer
+
+func (obs observers) OnRunStart(rcp recipe.Recipe) {
+	for _, o := range obs {
+		o.OnRunStart(rcp)
+	}
+}
+
+func (obs observers) OnRecordExtracted(recipeName string, record models.Record) {
+	for _, o := range obs {
+		o.OnRecordExtracted(recipeName, record)
+	}
+}
+
+func (obs observers) OnRecordProcessed(recipeName, processorName string, record models.Record, err error) {
+	for _, o := range obs {
+		o.OnRecordProcessed(recipeName, processorName, record, err)
+	}
+}
+
+func (obs observers) OnSinkBatch(recipeName string, batch SinkBatch) {
+	for _, o := range obs {
+		o.OnSinkBatch(recipeName, batch)
+	}
+}
+
+func (obs observers) OnRunEnd(run Run) {
+	for _, o := range obs {
+		o.OnRunEnd(run)
+	}
+}
+
+// isNilObserver returns whether the observer is a nil interface or a nil pointer,
+// zero values of other types are valid observers, such as stateless struct values.
+func isNilObserver(observer Observer) bool {
+	if observer == nil {
+		return true
+	}
+	v := reflect.ValueOf(observer)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"errors"
+
+	"github.com/odpf/meteor/plugins"
+)
+
+// maskedError is an error with the sensitive values of a recipe masked in its message and in the messages
+// of the errors it wraps, it still matches the original errors so their type can be checked.
+type maskedError struct {
+	err    error
+	values []string
+}
+
+func (e maskedError) Error() string {
+	return plugins.MaskString(e.err.Error(), e.values)
+}
+
+// Unwrap returns the cause of the error masked as well, as reports print each error of the chain.
+func (e maskedError) Unwrap() error {
+	return maskError(errors.Unwrap(e.err), e.values)
+}
+
+func (e maskedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+func (e maskedError) As(target interface{}) bool {
+	return errors.As(e.err, target)
+}
+
+// maskError returns the error with the sensitive values masked in its message and in its chain,
+// errors without any of the values are returned as they are.
+func maskError(err error, values []string) error {
+	for cause := err; cause != nil; cause = errors.Unwrap(cause) {
+		if msg := cause.Error(); plugins.MaskString(msg, values) != msg {
+			return maskedError{err: err, values: values}
+		}
+	}
+
+	return err
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/odpf/meteor/plugins"
+	"github.com/odpf/meteor/recipe"
 )
 
 const (
@@ -16,6 +17,8 @@ const (
 type retrier struct {
 	maxRetries      int
 	initialInterval time.Duration
+	maxInterval     time.Duration
+	jitter          float64
 }
 
 func newRetrier(maxRetries int, initialInterval time.Duration) *retrier {
@@ -33,6 +36,29 @@ func newRetrier(maxRetries int, initialInterval time.Duration) *retrier {
 	return r
 }
 
+// withPolicy returns a retrier with the fields set in the retry policy of a sink overriding the ones of r.
+func (r *retrier) withPolicy(policy *recipe.RetryPolicy) *retrier {
+	if policy == nil {
+		return r
+	}
+
+	pr := *r
+	if policy.MaxAttempts > 0 {
+		pr.maxRetries = policy.MaxAttempts - 1
+	}
+	if policy.InitialInterval > 0 {
+		pr.initialInterval = policy.InitialInterval
+	}
+	if policy.MaxInterval > 0 {
+		pr.maxInterval = policy.MaxInterval
+	}
+	if policy.Jitter > 0 {
+		pr.jitter = policy.Jitter
+	}
+
+	return &pr
+}
+
 func (r *retrier) retry(operation func() error, notify func(e error, d time.Duration)) error {
 	bo := backoff.WithMaxRetries(r.createExponentialBackoff(r.initialInterval), uint64(r.maxRetries))
 	return backoff.RetryNotify(func() error {
@@ -40,6 +66,10 @@ func (r *retrier) retry(operation func() error, notify func(e error, d time.Dura
 		if err == nil {
 			return err
 		}
+		// PermanentError is never retried, even if it wraps a RetryError
+		if errors.Is(err, plugins.PermanentError{}) {
+			return backoff.Permanent(err)
+		}
 		// if err is RetryError, returns err directly to retry
 		if errors.Is(err, plugins.RetryError{}) {
 			return err
@@ -52,8 +82,11 @@ func (r *retrier) retry(operation func() error, notify func(e error, d time.Dura
 func (r *retrier) createExponentialBackoff(initialInterval time.Duration) backoff.BackOff {
 	ebo := backoff.NewExponentialBackOff()
 	ebo.InitialInterval = initialInterval // first interval duration to be used
-	ebo.RandomizationFactor = 0           // to make sure we get constant increment in interval instead of random
+	ebo.RandomizationFactor = r.jitter    // 0 by default, to make sure we get constant increment in interval instead of random
 	ebo.Multiplier = 5                    // interval multiplier e.g. 5s -> 25s -> 125s -> 625s
+	if r.maxInterval > 0 {
+		ebo.MaxInterval = r.maxInterval
+	}
 
 	return ebo
 }
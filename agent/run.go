@@ -1,6 +1,12 @@
 package agent
 
-import "github.com/odpf/meteor/recipe"
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/odpf/meteor/recipe"
+)
 
 // TaskType is the type of task
 type TaskType string
@@ -20,5 +26,122 @@ type Run struct {
 	Error        error         `json:"error"`
 	DurationInMs int           `json:"duration_in_ms"`
 	RecordCount  int           `json:"record_count"`
+	DroppedCount int           `json:"dropped_count"`
+	SkippedCount int           `json:"skipped_count"`
+	Sources      []SourceRun   `json:"sources"`
+	Changes      *ChangeRun    `json:"changes,omitempty"`
+	DeletedCount int           `json:"deleted_count,omitempty"`
+	Sinks        []SinkRun     `json:"sinks"`
 	Success      bool          `json:"success"`
 }
+
+// SinkFailureCount returns the number of records the sinks of the run failed to send. A run goes on when sinks
+// reject records unless STOP_ON_SINK_ERROR is set, so a successful run can still have records its sinks did not send.
+func (r Run) SinkFailureCount() (count int) {
+	for _, sink := range r.Sinks {
+		count += sink.FailureCount
+	}
+	return count
+}
+
+// SourceRun contains the number of records a source extracted in a run, and the error it failed with.
+type SourceRun struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	RecordCount int    `json:"record_count"`
+	Error       error  `json:"error"`
+}
+
+// SinkRun contains the number of records a sink sent and failed to send in a run, the number of its calls
+// that timed out, and how long the run waited for the sink to have room for more records.
+type SinkRun struct {
+	Name         string `json:"name"`
+	SuccessCount int    `json:"success_count"`
+	FailureCount int    `json:"failure_count"`
+	TimeoutCount int    `json:"timeout_count,omitempty"`
+	BlockedInMs  int    `json:"blocked_in_ms,omitempty"`
+	SpilledCount int    `json:"spilled_count,omitempty"`
+}
+
+// sourceCounter counts the records of a source while the run is in progress
+type sourceCounter struct {
+	records int64
+	mu      sync.Mutex
+	err     error
+}
+
+func (c *sourceCounter) add() {
+	atomic.AddInt64(&c.records, 1)
+}
+
+func (c *sourceCounter) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *sourceCounter) error() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *sourceCounter) sourceRun(sr recipe.PluginRecipe) SourceRun {
+	return SourceRun{
+		ID:          sr.SourceID(),
+		Name:        sr.Name,
+		RecordCount: int(atomic.LoadInt64(&c.records)),
+		Error:       c.error(),
+	}
+}
+
+// firstSourceError returns the error of the first source that failed, in the order of the recipe.
+func firstSourceError(counters []*sourceCounter) error {
+	for _, c := range counters {
+		if err := c.error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allSourcesFailed returns true when every source failed.
+func allSourcesFailed(counters []*sourceCounter) bool {
+	for _, c := range counters {
+		if c.error() == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// sinkCounter counts the records of a sink while the run is in progress
+type sinkCounter struct {
+	success  int64
+	failure  int64
+	timeouts int64
+	buffer   bufferStats
+}
+
+func (c *sinkCounter) add(success bool, count int) {
+	if success {
+		atomic.AddInt64(&c.success, int64(count))
+		return
+	}
+	atomic.AddInt64(&c.failure, int64(count))
+}
+
+func (c *sinkCounter) timeout() {
+	atomic.AddInt64(&c.timeouts, 1)
+}
+
+func (c *sinkCounter) sinkRun(name string) SinkRun {
+	return SinkRun{
+		Name:         name,
+		SuccessCount: int(atomic.LoadInt64(&c.success)),
+		FailureCount: int(atomic.LoadInt64(&c.failure)),
+		TimeoutCount: int(atomic.LoadInt64(&c.timeouts)),
+		BlockedInMs:  int(time.Duration(atomic.LoadInt64(&c.buffer.blocked)).Milliseconds()),
+		SpilledCount: int(atomic.LoadInt64(&c.buffer.spilled)),
+	}
+}
@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/odpf/meteor/models"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// spillQueue is a FIFO queue of records kept in a temporary file, it is not safe for concurrent use.
+// Records are encoded as protobuf Any, prefixed with their length, so they are decoded back to their asset type.
+type spillQueue struct {
+	dir      string
+	file     *os.File
+	readOff  int64
+	writeOff int64
+	count    int
+	// next is the record at readOff once decoded by peek, until it is popped
+	next *models.Record
+}
+
+// newSpillQueue returns a queue creating its file in dir once a record is pushed, the temp dir is used when dir is empty.
+func newSpillQueue(dir string) *spillQueue {
+	return &spillQueue{dir: dir}
+}
+
+// push appends the record to the end of the queue.
+func (q *spillQueue) push(record models.Record) error {
+	msg, ok := record.Data().(proto.Message)
+	if !ok {
+		return errors.Errorf("record of type %T is not a protobuf message", record.Data())
+	}
+	wrapped, err := anypb.New(msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to wrap record")
+	}
+	data, err := proto.Marshal(wrapped)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode record")
+	}
+
+	if q.file == nil {
+		if q.file, err = os.CreateTemp(q.dir, "meteor-spill-*"); err != nil {
+			return errors.Wrap(err, "failed to create spill file")
+		}
+	}
+	buf := make([]byte, binary.MaxVarintLen64+len(data))
+	n := binary.PutUvarint(buf, uint64(len(data)))
+	n += copy(buf[n:], data)
+	if _, err := q.file.WriteAt(buf[:n], q.writeOff); err != nil {
+		return errors.Wrap(err, "failed to write spill file")
+	}
+	q.writeOff += int64(n)
+	q.count++
+
+	return nil
+}
+
+// peek returns the record at the front of the queue without removing it, false when the queue is empty.
+func (q *spillQueue) peek() (models.Record, bool, error) {
+	if q.count == 0 {
+		return models.Record{}, false, nil
+	}
+	if q.next != nil {
+		return *q.next, true, nil
+	}
+
+	header := make([]byte, binary.MaxVarintLen64)
+	n, err := q.file.ReadAt(header, q.readOff)
+	if err != nil && err != io.EOF {
+		return models.Record{}, false, errors.Wrap(err, "failed to read spill file")
+	}
+	size, headerLen := binary.Uvarint(header[:n])
+	if headerLen <= 0 {
+		return models.Record{}, false, errors.New("invalid record length in spill file")
+	}
+	data := make([]byte, size)
+	if _, err := q.file.ReadAt(data, q.readOff+int64(headerLen)); err != nil {
+		return models.Record{}, false, errors.Wrap(err, "failed to read spill file")
+	}
+
+	var wrapped anypb.Any
+	if err := proto.Unmarshal(data, &wrapped); err != nil {
+		return models.Record{}, false, errors.Wrap(err, "failed to decode record")
+	}
+	msg, err := wrapped.UnmarshalNew()
+	if err != nil {
+		return models.Record{}, false, errors.Wrap(err, "failed to decode record")
+	}
+	metadata, ok := msg.(models.Metadata)
+	if !ok {
+		return models.Record{}, false, errors.Errorf("record of type %T is not metadata", msg)
+	}
+
+	record := models.NewRecord(metadata)
+	q.next = &record
+	q.readOff += int64(headerLen) + int64(size)
+	return record, true, nil
+}
+
+// pop removes the record at the front of the queue, it has to be peeked first.
+// The file is truncated once the queue is empty, so it does not grow for the whole run.
+func (q *spillQueue) pop() error {
+	if q.next == nil {
+		return errors.New("spill queue: cannot pop a record not peeked")
+	}
+	q.next = nil
+	q.count--
+	if q.count > 0 {
+		return nil
+	}
+
+	q.readOff, q.writeOff = 0, 0
+	if err := q.file.Truncate(0); err != nil {
+		return errors.Wrap(err, "failed to truncate spill file")
+	}
+	return nil
+}
+
+// len returns the number of records in the queue.
+func (q *spillQueue) len() int {
+	return q.count
+}
+
+// close removes the file of the queue, records left in it are lost.
+func (q *spillQueue) close() error {
+	if q.file == nil {
+		return nil
+	}
+	q.file.Close()
+	return os.Remove(q.file.Name())
+}
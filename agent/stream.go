@@ -3,102 +3,443 @@ package agent
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/odpf/meteor/models"
 	"github.com/pkg/errors"
 )
 
+// errSkipRecord is returned by a middleware to drop a record without closing the stream.
+var errSkipRecord = errors.New("record skipped")
+
 type streamMiddleware func(src models.Record) (dst models.Record, err error)
 type subscriber struct {
-	callback  func([]models.Record) error
-	channel   chan models.Record
-	batchSize int
+	callback func([]models.Record) error
+	channel  chan models.Record
+	batch    batchOptions
+	stats    *bufferStats
+	// pending is the number of records sent to the subscriber that are not yet handed to the callback
+	pending int64
+	// exited is closed once the subscriber stops receiving records
+	exited chan struct{}
+
+	// spill holds the records of a spilling subscriber while its buffer is full, in order
+	spill      *spillQueue
+	spillMu    sync.Mutex
+	spillReady chan struct{}
+
+	// closeMu guards busy and abandoned, the onClose callbacks never run while the callback is busy
+	closeMu   sync.Mutex
+	busy      bool
+	abandoned bool
+	onCloses  []func()
+	closeOnce sync.Once
+}
+
+// bufferOptions are the options of the buffer of records waiting for a subscriber busy with previous batches.
+type bufferOptions struct {
+	// size is the max number of records in the buffer, 0 means records are handed to the subscriber one at a time.
+	size int
+	// spill writes records to a temporary file in spillDir when the buffer is full, instead of blocking the stream.
+	spill    bool
+	spillDir string
+	// stats is where the use of the buffer is counted, it can be nil.
+	stats *bufferStats
+}
+
+// bufferStats counts how the buffer of a subscriber was used.
+type bufferStats struct {
+	// blocked is the time the stream waited for room in the buffer, in nanoseconds
+	blocked int64
+	spilled int64
+}
+
+// batchOptions are the limits of a subscriber's batch, the batch is flushed when any of them is reached.
+type batchOptions struct {
+	size          int
+	maxBytes      int
+	flushInterval time.Duration
 }
 
 type stream struct {
 	middlewares []streamMiddleware
 	subscribers []*subscriber
-	onCloses    []func()
-	closed      bool
+	done        chan struct{}
+	drained     chan struct{}
+	abandoned   chan struct{}
+	sendMu      sync.RWMutex
+	// pushMu runs records through the middlewares one at a time, as extractors of several sources push concurrently
+	pushMu      sync.Mutex
+	closeOnce   sync.Once
+	abandonOnce sync.Once
+	dropped     int64
+	skipped     int64
+	mu          sync.Mutex
 	err         error
 }
 
 func newStream() *stream {
-	return &stream{}
+	return &stream{
+		done:      make(chan struct{}),
+		drained:   make(chan struct{}),
+		abandoned: make(chan struct{}),
+	}
 }
 
-// subscribe() will register callback with a batch size to the emitter.
+// subscribe() will register callback with batch and buffer options to the emitter.
 // Calling this will not start listening yet, use broadcast() to start sending data to subscriber.
-func (s *stream) subscribe(callback func(batchedData []models.Record) error, batchSize int) *stream {
-	s.subscribers = append(s.subscribers, &subscriber{
-		callback:  callback,
-		batchSize: batchSize,
-		channel:   make(chan models.Record),
-	})
+func (s *stream) subscribe(callback func(batchedData []models.Record) error, opts batchOptions, buffer bufferOptions) *subscriber {
+	l := &subscriber{
+		callback: callback,
+		batch:    opts,
+		channel:  make(chan models.Record, buffer.size),
+		stats:    buffer.stats,
+		exited:   make(chan struct{}),
+	}
+	if l.stats == nil {
+		l.stats = new(bufferStats)
+	}
+	if buffer.spill {
+		l.spill = newSpillQueue(buffer.spillDir)
+		l.spillReady = make(chan struct{}, 1)
+	}
+	s.subscribers = append(s.subscribers, l)
 
-	return s
+	return l
 }
 
-// onClose() is used to register callback for after stream is closed.
-func (s *stream) onClose(callback func()) *stream {
-	s.onCloses = append(s.onCloses, callback)
+// onClose() is used to register callback for after the subscriber is done.
+func (l *subscriber) onClose(callback func()) *subscriber {
+	l.onCloses = append(l.onCloses, callback)
 
-	return s
+	return l
+}
+
+// begin marks the callback of the subscriber busy, it returns false once the subscriber is abandoned.
+func (l *subscriber) begin() bool {
+	l.closeMu.Lock()
+	defer l.closeMu.Unlock()
+	if l.abandoned {
+		return false
+	}
+	l.busy = true
+
+	return true
+}
+
+// end marks the callback of the subscriber done, closing the subscriber if it was abandoned meanwhile.
+func (l *subscriber) end() {
+	l.closeMu.Lock()
+	l.busy = false
+	abandoned := l.abandoned
+	l.closeMu.Unlock()
+
+	if abandoned {
+		l.close()
+	}
+}
+
+// abandon stops the subscriber from running its callback again. It is closed right away when its callback is not busy,
+// or by end() once the busy callback returns, so onClose callbacks such as closing a sink never run during the callback.
+func (l *subscriber) abandon() {
+	l.closeMu.Lock()
+	l.abandoned = true
+	busy := l.busy
+	l.closeMu.Unlock()
+
+	if !busy {
+		l.close()
+	}
+}
+
+// close runs the onClose callbacks of the subscriber once.
+func (l *subscriber) close() {
+	l.closeOnce.Do(func() {
+		for _, onClose := range l.onCloses {
+			onClose()
+		}
+	})
 }
 
 // broadcast() will start listening to emitter for any pushed data.
 // This process is blocking, so most times you would want to call this inside a goroutine.
+// It returns once every subscriber has flushed its last batch after the stream is closed,
+// or once the stream is abandoned, and runs the onClose callbacks of the subscribers before returning.
+// The onClose callbacks of a subscriber abandoned while its callback is busy run once the callback returns.
 func (s *stream) broadcast() error {
-	var wg sync.WaitGroup
+	var wg, feeders sync.WaitGroup
 	for _, l := range s.subscribers {
+		if l.spill != nil {
+			feeders.Add(1)
+			go func(l *subscriber) {
+				defer feeders.Done()
+				s.feed(l)
+			}(l)
+		}
+
 		wg.Add(1)
 		go func(l *subscriber) {
 			defer func() {
 				if r := recover(); r != nil {
 					s.closeWithError(fmt.Errorf("%s", r))
 				}
+				close(l.exited)
 				wg.Done()
 			}()
 
-			batch := newBatch(l.batchSize)
-			// listen to channel and emit data to subscriber callback if batch is full
-			for d := range l.channel {
-				if err := batch.add(d); err != nil {
-					s.closeWithError(err)
+			batch := newBatch(l.batch.size, l.batch.maxBytes)
+			flush := func() {
+				data := batch.flush()
+				// records of an abandoned subscriber were already counted as dropped
+				if !l.begin() {
+					return
 				}
-				if batch.isFull() {
-					if err := l.callback(batch.flush()); err != nil {
-						s.closeWithError(err)
-					}
+				defer l.end()
+				err := l.callback(data)
+				atomic.AddInt64(&l.pending, -int64(len(data)))
+				if err != nil {
+					s.closeWithError(err)
 				}
 			}
 
-			// emit leftover data in the batch if any after channel is closed
-			if !batch.isEmpty() {
-				if err := l.callback(batch.flush()); err != nil {
-					s.closeWithError(err)
+			// a nil channel blocks forever, so there is no time based flush without an interval
+			var tick <-chan time.Time
+			if l.batch.flushInterval > 0 {
+				ticker := time.NewTicker(l.batch.flushInterval)
+				defer ticker.Stop()
+				tick = ticker.C
+			}
+
+			// listen to channel and emit data to subscriber callback if batch is full
+			for {
+				select {
+				case d, open := <-l.channel:
+					if !open {
+						// the channel is closed once the stream is closed and the records buffered for the subscriber are received,
+						// emit leftover data in the batch if any
+						if !batch.isEmpty() {
+							flush()
+						}
+						return
+					}
+					if !batch.fits(d) {
+						flush()
+					}
+					if err := batch.add(d); err != nil {
+						atomic.AddInt64(&l.pending, -1)
+						atomic.AddInt64(&s.dropped, 1)
+						s.closeWithError(err)
+						continue
+					}
+					if batch.isFull() {
+						flush()
+					}
+				case <-tick:
+					if !batch.isEmpty() {
+						flush()
+					}
 				}
 			}
 		}(l)
 	}
 
-	wg.Wait()
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
 
-	return s.err
+	select {
+	case <-finished:
+		for _, l := range s.subscribers {
+			l.close()
+		}
+	case <-s.abandoned:
+		// records still held by subscribers will not reach their callbacks
+		for _, l := range s.subscribers {
+			atomic.AddInt64(&s.dropped, atomic.LoadInt64(&l.pending))
+			l.abandon()
+		}
+	}
+	// spill files are removed once the feeders return
+	feeders.Wait()
+
+	return s.error()
 }
 
 // push() will run the record through all the registered middleware
 // and emit the record to all registered subscribers.
+// Records pushed after the stream is closed are dropped. It is safe for concurrent use.
 func (s *stream) push(data models.Record) {
+	s.pushMu.Lock()
+	defer s.pushMu.Unlock()
+
+	if s.isClosed() {
+		atomic.AddInt64(&s.dropped, int64(len(s.subscribers)))
+		return
+	}
+
 	data, err := s.runMiddlewares(data)
+	if errors.Is(err, errUnchangedRecord) {
+		return
+	}
+	if errors.Is(err, errSkipRecord) {
+		atomic.AddInt64(&s.skipped, 1)
+		return
+	}
 	if err != nil {
-		s.err = errors.Wrap(err, "emitter: error running middleware")
-		s.Close()
+		s.closeWithError(errors.Wrap(err, "emitter: error running middleware"))
 		return
 	}
 
+	if err := s.send(data); err != nil {
+		s.closeWithError(err)
+	}
+}
+
+// pushUnprocessed emits the record to all registered subscribers without running the middlewares,
+// for records made by the agent such as tombstones. It is safe for concurrent use.
+func (s *stream) pushUnprocessed(data models.Record) {
+	s.pushMu.Lock()
+	defer s.pushMu.Unlock()
+
+	if s.isClosed() {
+		atomic.AddInt64(&s.dropped, int64(len(s.subscribers)))
+		return
+	}
+	if err := s.send(data); err != nil {
+		s.closeWithError(err)
+	}
+}
+
+// send hands the record to every subscriber. It waits for room in the buffer of a subscriber
+// when it is full, unless the subscriber spills records, and drops the record once the stream is closed.
+func (s *stream) send(data models.Record) (err error) {
+	s.sendMu.RLock()
+	defer s.sendMu.RUnlock()
+
 	for _, l := range s.subscribers {
-		l.channel <- data
+		if s.isClosed() {
+			atomic.AddInt64(&s.dropped, 1)
+			continue
+		}
+		atomic.AddInt64(&l.pending, 1)
+
+		if l.spill != nil {
+			if spillErr := l.offer(data); spillErr != nil {
+				atomic.AddInt64(&l.pending, -1)
+				atomic.AddInt64(&s.dropped, 1)
+				err = errors.Wrap(spillErr, "failed to spill record")
+			}
+			continue
+		}
+
+		select {
+		case l.channel <- data:
+			continue
+		default:
+		}
+		start := time.Now()
+		select {
+		case l.channel <- data:
+		case <-s.done:
+			atomic.AddInt64(&l.pending, -1)
+			atomic.AddInt64(&s.dropped, 1)
+		}
+		atomic.AddInt64(&l.stats.blocked, int64(time.Since(start)))
+	}
+
+	return err
+}
+
+// offer hands the record to the buffer of a spilling subscriber, or to its spill queue when the buffer is full
+// or records are already waiting in the queue, so the subscriber receives them in order.
+func (l *subscriber) offer(data models.Record) error {
+	l.spillMu.Lock()
+	defer l.spillMu.Unlock()
+
+	if l.spill.len() == 0 {
+		select {
+		case l.channel <- data:
+			return nil
+		default:
+		}
+	}
+	if err := l.spill.push(data); err != nil {
+		return err
+	}
+	atomic.AddInt64(&l.stats.spilled, 1)
+
+	select {
+	case l.spillReady <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// feed moves the spilled records of the subscriber to its buffer, in order, while the subscriber receives records.
+// It closes the channel of the subscriber once the stream is drained and no spilled record is left,
+// or once the stream is abandoned, and removes the spill file before returning.
+func (s *stream) feed(l *subscriber) {
+	defer func() {
+		l.spillMu.Lock()
+		defer l.spillMu.Unlock()
+		l.spill.close()
+	}()
+
+	for {
+		l.spillMu.Lock()
+		data, ok, err := l.spill.peek()
+		left := l.spill.len()
+		l.spillMu.Unlock()
+		if err != nil {
+			// spilled records cannot be read back, so they will not reach the subscriber
+			atomic.AddInt64(&l.pending, -int64(left))
+			atomic.AddInt64(&s.dropped, int64(left))
+			s.closeWithError(errors.Wrap(err, "failed to read spilled record"))
+			<-s.drained
+			close(l.channel)
+			return
+		}
+
+		if !ok {
+			select {
+			case <-l.spillReady:
+			case <-s.drained:
+				// nothing is spilled once the stream is drained
+				l.spillMu.Lock()
+				left := l.spill.len()
+				l.spillMu.Unlock()
+				if left > 0 {
+					continue
+				}
+				close(l.channel)
+				return
+			case <-l.exited:
+				return
+			case <-s.abandoned:
+				close(l.channel)
+				return
+			}
+			continue
+		}
+
+		// the record stays in the queue until it is in the buffer, so records offered meanwhile are spilled after it
+		select {
+		case l.channel <- data:
+		case <-l.exited:
+			return
+		case <-s.abandoned:
+			close(l.channel)
+			return
+		}
+		l.spillMu.Lock()
+		err = l.spill.pop()
+		l.spillMu.Unlock()
+		if err != nil {
+			s.closeWithError(err)
+		}
 	}
 }
 
@@ -110,26 +451,73 @@ func (s *stream) setMiddleware(m streamMiddleware) *stream {
 }
 
 func (s *stream) closeWithError(err error) {
+	s.mu.Lock()
 	s.err = err
+	s.mu.Unlock()
 	s.Close()
 }
 
 // Close the emitter and signalling all subscriber of the event.
+// Subscribers receive the records left in their buffer and flush their remaining batch before broadcast() returns.
 func (s *stream) Close() {
-	if s.closed {
-		return
-	}
+	s.closeOnce.Do(func() {
+		close(s.done)
+		// waits for records being sent, nothing is sent to subscribers once the stream is done
+		s.sendMu.Lock()
+		s.sendMu.Unlock()
+		for _, l := range s.subscribers {
+			// channels of spilling subscribers are closed by feed() once their spilled records are received
+			if l.spill == nil {
+				close(l.channel)
+			}
+		}
+		close(s.drained)
+	})
+}
 
-	for _, l := range s.subscribers {
-		close(l.channel)
+// closeWithTimeout closes the stream and abandons it if subscribers
+// have not flushed their remaining batches within the timeout.
+// A timeout of 0 waits for the subscribers indefinitely.
+func (s *stream) closeWithTimeout(timeout time.Duration) {
+	s.Close()
+	if timeout > 0 {
+		time.AfterFunc(timeout, s.abandon)
 	}
-	s.closed = true
+}
 
-	for _, onClose := range s.onCloses {
-		onClose()
+// abandon stops broadcast() from waiting for the subscribers.
+func (s *stream) abandon() {
+	s.abandonOnce.Do(func() {
+		close(s.abandoned)
+	})
+}
+
+// droppedCount returns the number of records that did not reach a subscriber, counted once per subscriber.
+func (s *stream) droppedCount() int {
+	return int(atomic.LoadInt64(&s.dropped))
+}
+
+// skippedCount returns the number of records dropped by middlewares.
+func (s *stream) skippedCount() int {
+	return int(atomic.LoadInt64(&s.skipped))
+}
+
+func (s *stream) isClosed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
 	}
 }
 
+func (s *stream) error() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
 func (s *stream) runMiddlewares(d models.Record) (res models.Record, err error) {
 	res = d
 	for _, middleware := range s.middlewares {
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// withTimeout runs fn with a context that is cancelled after the timeout.
+// It returns a TimeoutError once the timeout is reached even if fn has not returned,
+// so a plugin ignoring its context cannot block the run. A timeout of 0 runs fn as is.
+func withTimeout(ctx context.Context, timeout time.Duration, stage TimeoutStage, pluginName string, fn func(ctx context.Context) error) error {
+	_, err := runWithTimeout(ctx, timeout, stage, pluginName, fn)
+	return err
+}
+
+// runWithTimeout is withTimeout also returning a channel closed once fn returns when fn is still running,
+// and nil otherwise.
+func runWithTimeout(ctx context.Context, timeout time.Duration, stage TimeoutStage, pluginName string, fn func(ctx context.Context) error) (running <-chan struct{}, err error) {
+	if timeout <= 0 {
+		return nil, fn(ctx)
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%s", r)
+			}
+		}()
+		done <- fn(tctx)
+	}()
+
+	timeoutErr := TimeoutError{Stage: stage, PluginName: pluginName, Timeout: timeout}
+	select {
+	case err := <-done:
+		if err != nil && ctx.Err() == nil && tctx.Err() == context.DeadlineExceeded {
+			return nil, timeoutErr
+		}
+		return nil, err
+	case <-tctx.Done():
+		// the parent context is done, not the timeout of this stage
+		if ctx.Err() != nil {
+			return returned, ctx.Err()
+		}
+		return returned, timeoutErr
+	}
+}
+
+// inFlight keeps the call of a plugin still running after its timeout,
+// so the plugin is neither called again nor closed while that call is running.
+type inFlight struct {
+	mu      sync.Mutex
+	running <-chan struct{}
+}
+
+// track keeps the channel runWithTimeout returned for the last call.
+func (f *inFlight) track(running <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.running = running
+}
+
+// wait waits for the call still running for at most the timeout, a timeout of 0 waits indefinitely.
+// It returns false if the call is still running.
+func (f *inFlight) wait(timeout time.Duration) bool {
+	f.mu.Lock()
+	running := f.running
+	f.mu.Unlock()
+	if running == nil {
+		return true
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-running:
+		f.mu.Lock()
+		if f.running == running {
+			f.running = nil
+		}
+		f.mu.Unlock()
+		return true
+	case <-expired:
+		return false
+	}
+}
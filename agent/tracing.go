@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/odpf/meteor/agent"
+
+// span attributes set by the agent
+const (
+	attrRecipe      = attribute.Key("meteor.recipe")
+	attrPlugin      = attribute.Key("meteor.plugin")
+	attrRecord      = attribute.Key("meteor.record")
+	attrRecordCount = attribute.Key("meteor.record_count")
+	attrAttempt     = attribute.Key("meteor.attempt")
+)
+
+func (r *Agent) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return r.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span, marking it failed when err is not nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/meteor/diff"
+	"github.com/odpf/meteor/recipe"
+	"github.com/spf13/cobra"
+)
+
+// DiffCmd creates a command object for comparing two extraction outputs
+func DiffCmd() *cobra.Command {
+	var (
+		format       string
+		ignoreFields []string
+		exitCode     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <old-file> <new-file>",
+		Short: "Compare the assets of two extraction outputs",
+		Long: heredoc.Doc(`
+			Compare the assets of two ndjson files written by the file sink.
+
+			Assets are matched by urn and reported as added, removed or changed.
+			Columns, owners and lineage edges are compared by their name or urn,
+			other fields by their path.`),
+		Example: heredoc.Doc(`
+			$ meteor diff old.ndjson new.ndjson
+
+			# print the differences as json
+			$ meteor diff old.ndjson new.ndjson --format json
+
+			# fail when the outputs differ, such as in a pull request check
+			$ meteor diff old.ndjson new.ndjson --exit-code
+		`),
+		Args: cobra.ExactArgs(2),
+		Annotations: map[string]string{
+			"group:core": "true",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !isDiffFormat(format) {
+				return fmt.Errorf("unknown diff format \"%s\", expected one of %s", format, strings.Join(diff.Formats, ", "))
+			}
+
+			oldAssets, err := diff.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read \"%s\": %w", args[0], err)
+			}
+			newAssets, err := diff.ReadFile(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to read \"%s\": %w", args[1], err)
+			}
+
+			result := diff.Compare(oldAssets, newAssets, ignoreFields)
+			if err := diff.Write(os.Stdout, format, result); err != nil {
+				return err
+			}
+
+			if exitCode && !result.Empty() {
+				// the error only sets the exit code, differences are already written
+				cmd.SilenceUsage = true
+				return newSilentError(fmt.Errorf("%d added, %d removed and %d changed assets", len(result.Added), len(result.Removed), len(result.Changed)))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", diff.FormatText, fmt.Sprintf("Output format, one of %s", strings.Join(diff.Formats, ", ")))
+	cmd.Flags().StringSliceVar(&ignoreFields, "ignore-fields", recipe.DefaultChangeIgnoredFields, "Dotted paths of the fields left out of the comparison")
+	cmd.Flags().BoolVar(&exitCode, "exit-code", false, "Exit with an error when the outputs differ")
+
+	return cmd
+}
+
+func isDiffFormat(format string) bool {
+	for _, f := range diff.Formats {
+		if f == format {
+			return true
+		}
+	}
+
+	return false
+}
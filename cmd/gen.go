@@ -50,6 +50,7 @@ func GenCmd(lg log.Logger) *cobra.Command {
 				TemplateFilePath: templatePath,
 				OutputDirPath:    outputDirPath,
 				Data:             data,
+				Schemas:          pluginSchemas(),
 			})
 		},
 	}
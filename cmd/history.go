@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/meteor/config"
+	"github.com/odpf/meteor/history"
+	"github.com/odpf/salt/log"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/salt/term"
+	"github.com/spf13/cobra"
+)
+
+// trendLookback is the number of runs of a recipe read to find its trends.
+const trendLookback = 50
+
+// HistoryCmd creates a command object for the "history" action.
+func HistoryCmd(lg log.Logger, cfg config.Config) *cobra.Command {
+	var (
+		configFile string
+		limit      int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history [recipe]",
+		Short: "Show past runs of recipes",
+		Long: heredoc.Doc(`
+			Show past runs of recipes, the latest first, kept by meteor run and meteor serve.
+
+			The latest run of each recipe shown is compared to its previous successful runs
+			to warn about recipes that keep failing, extract no records or fewer records than usual,
+			or take at least twice as long as usual.`),
+		Example: heredoc.Doc(`
+			$ meteor history
+
+			# show the last 5 runs of a recipe
+			$ meteor history sample-recipe --limit 5
+		`),
+		Args: cobra.MaximumNArgs(1),
+		Annotations: map[string]string{
+			"group:core": "true",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFile != "" {
+				var err error
+				cfg, err = config.Load(configFile)
+				if err != nil {
+					return err
+				}
+			}
+			if cfg.HistoryPath == "" {
+				return errors.New("no run history path, set HISTORY_PATH in the config")
+			}
+			if _, err := os.Stat(cfg.HistoryPath); err != nil {
+				return fmt.Errorf("no run history found in [%s]", cfg.HistoryPath)
+			}
+
+			store, err := history.NewStore(cfg.HistoryPath)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			var recipeName string
+			if len(args) > 0 {
+				recipeName = args[0]
+			}
+			entries, err := store.List(recipeName, limit)
+			if err != nil {
+				return err
+			}
+
+			cs := term.NewColorScheme()
+			if len(entries) == 0 {
+				fmt.Println(cs.WarningIcon(), cs.Yellow("No run found"))
+				return nil
+			}
+
+			table := [][]string{{"ID", "Status", "Recipe", "Source", "Started", "Duration(ms)", "Records", "Sinks", "Error"}}
+			var names []string
+			seen := make(map[string]bool)
+			for _, entry := range entries {
+				status := cs.SuccessIcon()
+				if !entry.Success {
+					status = cs.FailureIcon()
+				}
+				table = append(table, []string{
+					strconv.FormatInt(entry.ID, 10),
+					status,
+					entry.Recipe,
+					cs.Grey(entry.Source),
+					cs.Grey(entry.StartedAt.Local().Format("2006-01-02 15:04:05")),
+					cs.Greyf("%d ms", entry.DurationInMs),
+					cs.Greyf(strconv.Itoa(entry.RecordCount)),
+					cs.Grey(sinkOutcomes(entry)),
+					cs.Grey(entry.Error),
+				})
+				if !seen[entry.Recipe] {
+					seen[entry.Recipe] = true
+					names = append(names, entry.Recipe)
+				}
+			}
+			fmt.Println()
+			printer.Table(os.Stdout, table)
+
+			var trends []history.Trend
+			for _, name := range names {
+				recipeEntries, err := store.List(name, trendLookback)
+				if err != nil {
+					return err
+				}
+				trends = append(trends, history.Trends(recipeEntries)...)
+			}
+			if len(trends) > 0 {
+				fmt.Println()
+				for _, trend := range trends {
+					fmt.Println(cs.WarningIcon(), cs.Yellowf("%s: %s (run %d)", trend.Recipe, trend.Message, trend.RunID))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "./meteor.yaml", "file path for agent level config")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Max number of runs to show, 0 shows all of them")
+
+	return cmd
+}
+
+// setupHistory returns the recorder adding runs to the run history, or nil when the history is disabled.
+// The history is optional to runs, so it is left out with a warning when it has no path or its store cannot be opened.
+func setupHistory(lg log.Logger, cfg config.Config) (recorder *history.Recorder, closeFn func()) {
+	if !cfg.HistoryEnabled {
+		return nil, func() {}
+	}
+	if cfg.HistoryPath == "" {
+		lg.Warn("run history is not recorded, HISTORY_PATH is not set")
+		return nil, func() {}
+	}
+
+	store, err := history.NewStore(cfg.HistoryPath)
+	if err != nil {
+		lg.Warn("run history is not recorded", "path", cfg.HistoryPath, "err", err.Error())
+		return nil, func() {}
+	}
+
+	return history.NewRecorder(store, lg), func() {
+		store.Close()
+	}
+}
+
+// sinkOutcomes describes the records each sink of the run succeeded and failed to send.
+func sinkOutcomes(entry history.Entry) string {
+	var outcomes []string
+	for _, sink := range entry.Sinks {
+		outcome := fmt.Sprintf("%s: %d", sink.Name, sink.SuccessCount)
+		if sink.FailureCount > 0 {
+			outcome += fmt.Sprintf(" (%d failed)", sink.FailureCount)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return strings.Join(outcomes, ", ")
+}
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/meteor/plugins"
 	"github.com/odpf/meteor/registry"
 	"github.com/odpf/salt/log"
 	"github.com/odpf/salt/printer"
@@ -29,6 +32,7 @@ func InfoCmd(lg log.Logger) *cobra.Command {
 
 // InfoSinkCmd creates a command object for listing sinks
 func InfoSinkCmd() *cobra.Command {
+	var schema bool
 	cmd := &cobra.Command{
 		Use:   "sink <name>",
 		Short: "Display sink information",
@@ -62,17 +66,19 @@ func InfoSinkCmd() *cobra.Command {
 				}
 			}
 			info, err := registry.Sinks.Info(name)
-			if err := inform("sinks", info.Summary, err); err != nil {
+			if err := inform("sinks", info, err, schema); err != nil {
 				return err
 			}
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&schema, "schema", false, "Print the JSON Schema of the plugin config")
 	return cmd
 }
 
 // InfoExtCmd creates a command object for listing extractors
 func InfoExtCmd() *cobra.Command {
+	var schema bool
 	cmd := &cobra.Command{
 		Use:   "extractor <name>",
 		Short: "Display extractor information",
@@ -107,17 +113,19 @@ func InfoExtCmd() *cobra.Command {
 				}
 			}
 			info, err := registry.Extractors.Info(name)
-			if err := inform("extractors", info.Summary, err); err != nil {
+			if err := inform("extractors", info, err, schema); err != nil {
 				return err
 			}
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&schema, "schema", false, "Print the JSON Schema of the plugin config")
 	return cmd
 }
 
 // InfoProccCmd creates a command object for listing processors
 func InfoProccCmd() *cobra.Command {
+	var schema bool
 	cmd := &cobra.Command{
 		Use:   "processor <name>",
 		Short: "Display processor information",
@@ -151,16 +159,17 @@ func InfoProccCmd() *cobra.Command {
 			}
 			info, err := registry.Processors.Info(name)
 
-			if err := inform("processors", info.Summary, err); err != nil {
+			if err := inform("processors", info, err, schema); err != nil {
 				return err
 			}
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&schema, "schema", false, "Print the JSON Schema of the plugin config")
 	return cmd
 }
 
-func inform(typ string, summary string, err error) error {
+func inform(typ string, info plugins.Info, err error, schema bool) error {
 	cs := term.NewColorScheme()
 
 	if err != nil {
@@ -169,7 +178,17 @@ func inform(typ string, summary string, err error) error {
 		return nil
 	}
 
-	out, err := printer.MarkdownWithWrap(summary, 130)
+	if schema {
+		if info.ConfigSchema == nil {
+			fmt.Println(cs.Yellowf("The plugin accepts any config, it has no config schema."))
+			return nil
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info.ConfigSchema)
+	}
+
+	out, err := printer.MarkdownWithWrap(info.Summary, 130)
 
 	if err != nil {
 		return err
@@ -8,8 +8,8 @@ import (
 	"github.com/odpf/meteor/plugins"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/mitchellh/mapstructure"
 	"github.com/odpf/meteor/agent"
-	"github.com/odpf/meteor/metrics"
 	"github.com/odpf/meteor/recipe"
 	"github.com/odpf/meteor/registry"
 	"github.com/odpf/salt/log"
@@ -17,10 +17,11 @@ import (
 	"github.com/odpf/salt/term"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 // LintCmd creates a command object for linting recipes
-func LintCmd(lg log.Logger, mt *metrics.StatsdMonitor) *cobra.Command {
+func LintCmd(lg log.Logger, observers []agent.Observer) *cobra.Command {
 	var (
 		report   [][]string
 		success  = 0
@@ -36,7 +37,10 @@ func LintCmd(lg log.Logger, mt *metrics.StatsdMonitor) *cobra.Command {
 			Check for issues specified recipes.
 
 			Linters are run on the recipe files in the specified path.
-			If no path is specified, the current directory is used.`),
+			If no path is specified, the current directory is used.
+
+			The config of each plugin is checked against the config schema of the plugin,
+			config keys the plugin does not know are reported as warnings.`),
 		Example: heredoc.Doc(`
 			$ meteor lint recipe.yml
 
@@ -55,7 +59,7 @@ func LintCmd(lg log.Logger, mt *metrics.StatsdMonitor) *cobra.Command {
 				ExtractorFactory: registry.Extractors,
 				ProcessorFactory: registry.Processors,
 				SinkFactory:      registry.Sinks,
-				Monitor:          mt,
+				Observers:        observers,
 				Logger:           lg,
 			})
 
@@ -72,11 +76,22 @@ func LintCmd(lg log.Logger, mt *metrics.StatsdMonitor) *cobra.Command {
 
 			// Run linters and generate report
 			for _, recipe := range recipes {
-				errs := runner.Validate(recipe)
+				schemaErrs := lintConfigs(recipe)
+				// config errors found by the schema are printed with their position instead
+				errs := withoutSchemaErrors(runner.Validate(recipe), schemaErrs)
+				errCount, warnCount := len(errs), 0
+				for _, schemaErr := range schemaErrs {
+					if schemaErr.Warning {
+						warnCount++
+					} else {
+						errCount++
+					}
+				}
 				var row []string
 				var icon string
 
-				if len(errs) == 0 {
+				printSchemaErrors(schemaErrs, recipe)
+				if errCount == 0 {
 					icon = cs.SuccessIcon()
 					success++
 				} else {
@@ -85,7 +100,7 @@ func LintCmd(lg log.Logger, mt *metrics.StatsdMonitor) *cobra.Command {
 					failures++
 				}
 
-				row = []string{fmt.Sprintf("%s  %s", icon, recipe.Name), cs.Greyf("(%d errors, 0 warnings)", len(errs))}
+				row = []string{fmt.Sprintf("%s  %s", icon, recipe.Name), cs.Greyf("(%d errors, %d warnings)", errCount, warnCount)}
 				report = append(report, row)
 			}
 
@@ -126,7 +141,10 @@ func printLintErrors(errs []error, rcp recipe.Recipe) {
 // printPluginErrors print the plugin's type error
 func printPluginErrors(rcp recipe.Recipe, notFoundError plugins.NotFoundError) {
 	if notFoundError.Type == plugins.PluginTypeExtractor {
-		printPluginError(rcp, rcp.Source, notFoundError)
+		plugin, exists := findPluginByName(rcp.AllSources(), notFoundError.Name)
+		if exists {
+			printPluginError(rcp, plugin, notFoundError)
+		}
 	} else if notFoundError.Type == plugins.PluginTypeProcessor {
 		plugin, exists := findPluginByName(rcp.Processors, notFoundError.Name)
 		if exists {
@@ -149,7 +167,10 @@ func printPluginError(rcp recipe.Recipe, plugin recipe.PluginRecipe, notFoundErr
 // printConfigErrors print the plugin's config error
 func printConfigErrors(rcp recipe.Recipe, invalidConfigError plugins.InvalidConfigError) {
 	if invalidConfigError.Type == plugins.PluginTypeExtractor {
-		printConfigError(rcp, rcp.Source.Node, invalidConfigError)
+		plugin, exists := findPluginByName(rcp.AllSources(), invalidConfigError.PluginName)
+		if exists {
+			printConfigError(rcp, plugin.Node, invalidConfigError)
+		}
 	} else if invalidConfigError.Type == plugins.PluginTypeProcessor {
 		plugin, exists := findPluginByName(rcp.Processors, invalidConfigError.PluginName)
 		if exists {
@@ -168,14 +189,104 @@ func printConfigError(rcp recipe.Recipe, pluginNode recipe.PluginNode, invalidCo
 	for _, configError := range invalidConfigError.Errors {
 		cfg, ok := pluginNode.Config[configError.Key]
 		if ok {
-			line := cfg.Line
-			fmt.Printf("%s: invalid %s %s config on line: %d\n", rcp.Name, invalidConfigError.PluginName, invalidConfigError.Type, line)
+			fmt.Printf("%s: invalid %s %s config on line: %d, column: %d\n", rcp.Name, invalidConfigError.PluginName, invalidConfigError.Type, cfg.Line, cfg.Column)
 		} else {
 			fmt.Printf("%s: invalid %s %s config: %s\n", rcp.Name, invalidConfigError.PluginName, invalidConfigError.Type, configError.Message)
 		}
 	}
 }
 
+// pluginSchemaError is a violation of the config schema of a plugin of a recipe
+type pluginSchemaError struct {
+	plugins.SchemaError
+	Type plugins.PluginType
+	Name string
+}
+
+// lintConfigs checks the config of each plugin of the recipe against the config schema of the plugin.
+// Plugins that are not found are reported by the validation of the recipe.
+func lintConfigs(rcp recipe.Recipe) (errs []pluginSchemaError) {
+	lint := func(typ plugins.PluginType, plugin recipe.PluginRecipe, info plugins.Info, err error) {
+		if err != nil || info.ConfigSchema == nil {
+			return
+		}
+		node := &plugin.Node.ConfigNode
+		if node.Kind == 0 {
+			// a plugin without config is reported at its name
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: plugin.Node.Name.Line, Column: plugin.Node.Name.Column}
+		}
+		for _, schemaErr := range info.ConfigSchema.ValidateNode(node) {
+			errs = append(errs, pluginSchemaError{SchemaError: schemaErr, Type: typ, Name: plugin.Name})
+		}
+	}
+
+	for _, src := range rcp.AllSources() {
+		info, err := registry.Extractors.Info(src.Name)
+		lint(plugins.PluginTypeExtractor, src, info, err)
+	}
+	for _, proc := range rcp.Processors {
+		info, err := registry.Processors.Info(proc.Name)
+		lint(plugins.PluginTypeProcessor, proc, info, err)
+	}
+	for _, sink := range rcp.Sinks {
+		info, err := registry.Sinks.Info(sink.Name)
+		lint(plugins.PluginTypeSink, sink, info, err)
+	}
+
+	return errs
+}
+
+// withoutSchemaErrors removes the config errors of the fields the schema found errors for,
+// as well as the errors decoding the config of a recipe with schema errors.
+func withoutSchemaErrors(errs []error, schemaErrs []pluginSchemaError) (filtered []error) {
+	reported := make(map[string]bool)
+	for _, schemaErr := range schemaErrs {
+		if !schemaErr.Warning {
+			reported[fmt.Sprintf("%s/%s/%s", schemaErr.Type, schemaErr.Name, schemaErr.Key)] = true
+		}
+	}
+	if len(reported) == 0 {
+		return errs
+	}
+
+	for _, err := range errs {
+		var (
+			invalidConfigError plugins.InvalidConfigError
+			decodeError        *mapstructure.Error
+		)
+		if errors.As(err, &decodeError) {
+			continue
+		}
+		if !errors.As(err, &invalidConfigError) || !invalidConfigError.HasError() {
+			filtered = append(filtered, err)
+			continue
+		}
+		var configErrors []plugins.ConfigError
+		for _, configError := range invalidConfigError.Errors {
+			if !reported[fmt.Sprintf("%s/%s/%s", invalidConfigError.Type, invalidConfigError.PluginName, configError.Key)] {
+				configErrors = append(configErrors, configError)
+			}
+		}
+		if len(configErrors) > 0 {
+			invalidConfigError.Errors = configErrors
+			filtered = append(filtered, invalidConfigError)
+		}
+	}
+
+	return filtered
+}
+
+// printSchemaErrors prints the violations of the config schemas with their position in the recipe
+func printSchemaErrors(errs []pluginSchemaError, rcp recipe.Recipe) {
+	for _, err := range errs {
+		level := "error"
+		if err.Warning {
+			level = "warning"
+		}
+		fmt.Printf("%s: %s \"%s\" config %s on line: %d, column: %d: %s\n", rcp.Name, err.Type, err.Name, level, err.Line, err.Column, err.Message)
+	}
+}
+
 // findPluginByName checks plugin by provided name
 func findPluginByName(plugins []recipe.PluginRecipe, name string) (plugin recipe.PluginRecipe, exists bool) {
 	for _, p := range plugins {
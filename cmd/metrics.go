@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/odpf/meteor/config"
+	"github.com/odpf/meteor/metrics"
+	"github.com/odpf/meteor/tracing"
+	"github.com/odpf/salt/log"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+// setupPrometheus creates the prometheus monitor. Its metrics are served on the configured address
+// until the returned close function is called, or pushed by it when a Pushgateway is configured.
+func setupPrometheus(lg log.Logger, cfg config.Config) (monitor *metrics.PrometheusMonitor, closeFn func(), err error) {
+	monitor = metrics.NewPrometheusMonitor(cfg.PrometheusNamespace)
+
+	if cfg.PrometheusPushgatewayURL != "" {
+		closeFn = func() {
+			if err := monitor.Push(cfg.PrometheusPushgatewayURL, cfg.PrometheusJob); err != nil {
+				lg.Error(err.Error(), "pushgateway", cfg.PrometheusPushgatewayURL)
+			}
+		}
+		return monitor, closeFn, nil
+	}
+
+	ln, err := net.Listen("tcp", cfg.PrometheusAddress)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to listen for prometheus metrics")
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", monitor.Handler())
+	srv := &http.Server{Handler: mux}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			lg.Error(err.Error(), "address", cfg.PrometheusAddress)
+		}
+	}()
+	lg.Info("serving prometheus metrics", "address", cfg.PrometheusAddress)
+
+	closeFn = func() {
+		srv.Close()
+	}
+	return monitor, closeFn, nil
+}
+
+// setupTracing sets the global TracerProvider, used by the agent and plugins, to export spans as configured.
+// The returned close function flushes the spans left.
+func setupTracing(lg log.Logger, cfg config.Config) (closeFn func(), err error) {
+	tp, closeProvider, err := tracing.NewProvider(context.Background(), tracing.Config{
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.TracingOTLPEndpoint,
+		OTLPInsecure: cfg.TracingOTLPInsecure,
+		FilePath:     cfg.TracingFilePath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(tp)
+
+	closeFn = func() {
+		if err := closeProvider(context.Background()); err != nil {
+			lg.Error(err.Error(), "exporter", cfg.TracingExporter)
+		}
+	}
+	return closeFn, nil
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/config"
+	"github.com/odpf/meteor/deadletter"
+	"github.com/odpf/meteor/recipe"
+	"github.com/odpf/meteor/registry"
+	"github.com/odpf/salt/log"
+	"github.com/odpf/salt/printer"
+	"github.com/odpf/salt/term"
+	"github.com/spf13/cobra"
+)
+
+// ReplayCmd creates a command object for the "replay" action.
+func ReplayCmd(lg log.Logger, cfg config.Config) *cobra.Command {
+	var (
+		report       [][]string
+		pathToConfig string
+		success      = 0
+		failures     = 0
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay <dead-letter-file> <recipe-path>",
+		Short: "Send dead letter records again to their sinks",
+		Long: heredoc.Doc(`
+			Send records from a dead letter file again to the sinks that rejected them.
+
+			Each dead letter entry is matched to its recipe by name and to the sink at the same
+			index in the recipe, so the recipes in the given path have to contain the original recipes.`),
+		Example: heredoc.Doc(`
+			$ meteor replay dead-letter.ndjson recipe.yml
+
+			# replay with recipes in a directory
+			$ meteor replay dead-letter.ndjson _recipes/
+		`),
+		Args: cobra.ExactArgs(2),
+		Annotations: map[string]string{
+			"group:core": "true",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs := term.NewColorScheme()
+			runner := agent.NewAgent(agent.Config{
+				ExtractorFactory:     registry.Extractors,
+				ProcessorFactory:     registry.Processors,
+				SinkFactory:          registry.Sinks,
+				Logger:               lg,
+				MaxRetries:           cfg.MaxRetries,
+				RetryInitialInterval: time.Duration(cfg.RetryInitialIntervalSeconds) * time.Second,
+			})
+
+			// Monitoring system signals and creating context
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			entries, err := deadletter.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println(cs.WarningIcon(), cs.Yellowf("No dead letter entry found in [%s]", args[0]))
+				return nil
+			}
+
+			recipes, err := recipe.NewReader(lg, pathToConfig, recipe.WithSecretResolver(setupSecrets(cfg))).Read(args[1])
+			if err != nil {
+				return err
+			}
+			recipesByName := make(map[string]recipe.Recipe)
+			for _, rcp := range recipes {
+				recipesByName[rcp.Name] = rcp
+			}
+
+			report = append(report, []string{"Status", "Recipe", "Sink", "Records", "Rejected at"})
+			for _, entry := range entries {
+				rcp, ok := recipesByName[entry.Recipe]
+				if ok {
+					err = runner.Replay(ctx, rcp, entry)
+				} else {
+					err = fmt.Errorf("could not find recipe \"%s\"", entry.Recipe)
+				}
+
+				icon := cs.SuccessIcon()
+				if err != nil {
+					lg.Error(err.Error(), "recipe", entry.Recipe, "sink", entry.Sink)
+					icon = cs.FailureIcon()
+					failures++
+				} else {
+					success++
+				}
+				report = append(report, []string{icon, entry.Recipe, cs.Grey(entry.Sink), cs.Greyf(strconv.Itoa(len(entry.Records))), cs.Grey(entry.Timestamp.Format(time.RFC3339))})
+			}
+
+			// Print the report
+			if failures > 0 {
+				fmt.Println("\nSome entries were not replayed successfully")
+			} else {
+				fmt.Println("\nAll entries were replayed successfully")
+			}
+			fmt.Printf("%d failing, %d successful, and %d total\n\n", failures, success, len(entries))
+			printer.Table(os.Stdout, report)
+
+			if failures > 0 {
+				// the error only sets the exit code, usage is not relevant to failing entries
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d entries failed to replay", failures, len(entries))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&pathToConfig, "var", "", "Path to Config file with env variables for recipe")
+
+	return cmd
+}
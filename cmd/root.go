@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/meteor/agent"
 	"github.com/odpf/meteor/config"
 	"github.com/odpf/meteor/metrics"
 	"github.com/odpf/meteor/plugins"
@@ -27,14 +29,14 @@ func New() *cobra.Command {
 	plugins.SetLog(lg)
 
 	// Setup statsd monitor to collect monitoring metrics
-	var mt *metrics.StatsdMonitor
+	var observers []agent.Observer
 	if cfg.StatsdEnabled {
 		client, err := metrics.NewStatsdClient(cfg.StatsdHost)
 		if err != nil {
 			fmt.Printf("ERROR: %s\n", err.Error())
 			os.Exit(exitError)
 		}
-		mt = metrics.NewStatsdMonitor(client, cfg.StatsdPrefix)
+		observers = append(observers, metrics.NewStatsdMonitor(client, cfg.StatsdPrefix))
 	}
 
 	var cmd = &cobra.Command{
@@ -69,9 +71,37 @@ func New() *cobra.Command {
 	cmd.AddCommand(GenCmd(lg))
 	cmd.AddCommand(ListCmd(lg))
 	cmd.AddCommand(InfoCmd(lg))
-	cmd.AddCommand(RunCmd(lg, mt, cfg))
-	cmd.AddCommand(LintCmd(lg, mt))
+	cmd.AddCommand(RunCmd(lg, observers, cfg))
+	cmd.AddCommand(ServeCmd(lg, observers, cfg))
+	cmd.AddCommand(ReplayCmd(lg, cfg))
+	cmd.AddCommand(HistoryCmd(lg, cfg))
+	cmd.AddCommand(LintCmd(lg, observers))
+	cmd.AddCommand(DiffCmd())
 	cmd.AddCommand(NewCmd(lg))
 
 	return cmd
 }
+
+// SilentError is an error meteor exits with without printing it,
+// returned by commands that already reported why they failed, so their output stays parsable.
+type SilentError struct {
+	err error
+}
+
+func newSilentError(err error) *SilentError {
+	return &SilentError{err: err}
+}
+
+func (e *SilentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *SilentError) Unwrap() error {
+	return e.err
+}
+
+// IsSilentError returns whether err only sets the exit code, as the command already reported it.
+func IsSilentError(err error) bool {
+	var silent *SilentError
+	return errors.As(err, &silent)
+}
@@ -3,33 +3,42 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/odpf/meteor/agent"
 	"github.com/odpf/meteor/config"
-	"github.com/odpf/meteor/metrics"
+	"github.com/odpf/meteor/deadletter"
 	"github.com/odpf/meteor/recipe"
 	"github.com/odpf/meteor/registry"
+	"github.com/odpf/meteor/report"
+	"github.com/odpf/meteor/state"
 	"github.com/odpf/salt/log"
 	"github.com/odpf/salt/printer"
 	"github.com/odpf/salt/term"
+	"github.com/pkg/errors"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
 
 // RunCmd creates a command object for the "run" action.
-func RunCmd(lg log.Logger, mt *metrics.StatsdMonitor, cfg config.Config) *cobra.Command {
+func RunCmd(lg log.Logger, observers []agent.Observer, cfg config.Config) *cobra.Command {
 	var (
-		report       [][]string
+		table        [][]string
 		pathToConfig string
 		success      = 0
 		failures     = 0
 		configFile   string
+		parallelism  int
+		dryRun       bool
+		reportFormat string
+		reportFile   string
 	)
 
 	cmd := &cobra.Command{
@@ -51,12 +60,34 @@ func RunCmd(lg log.Logger, mt *metrics.StatsdMonitor, cfg config.Config) *cobra.
 
 			# run all recipes in the current directory
 			$ meteor run .
+
+			# preview what sinks would receive without sending anything
+			$ meteor run recipe.yml --dry-run
+
+			# write a junit report of the run for CI
+			$ meteor run _recipes/ --report-format junit --report-file report.xml
+
+			# run at most 10 recipes of the same group at a time
+			$ meteor run _recipes/ --max-parallelism 10
 		`),
 		Args: cobra.ExactArgs(1),
 		Annotations: map[string]string{
 			"group:core": "true",
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if reportFile != "" && reportFormat == "" {
+				reportFormat = report.FormatJSON
+			}
+			if reportFormat != "" && !isReportFormat(reportFormat) {
+				return fmt.Errorf("unknown report format \"%s\", expected one of %s", reportFormat, strings.Join(report.Formats, ", "))
+			}
+			// a report written to stdout replaces the table, other output goes to stderr to keep it parsable
+			reportToStdout := reportFormat != "" && reportFile == ""
+			out := io.Writer(os.Stdout)
+			if reportToStdout {
+				out = os.Stderr
+			}
+
 			if configFile != "" {
 				var err error
 				cfg, err = config.Load(configFile)
@@ -65,73 +96,201 @@ func RunCmd(lg log.Logger, mt *metrics.StatsdMonitor, cfg config.Config) *cobra.
 				}
 			}
 
+			if parallelism > 0 {
+				cfg.MaxParallelism = parallelism
+			}
+
+			runObservers := append([]agent.Observer{}, observers...)
+			if cfg.PrometheusEnabled {
+				monitor, closeMonitor, err := setupPrometheus(lg, cfg)
+				if err != nil {
+					return err
+				}
+				// pushes the metrics, or stops serving them, once the recipes are done
+				defer closeMonitor()
+				runObservers = append(runObservers, monitor)
+			}
+
+			// dry runs send nothing, so they are left out of the run history
+			if !dryRun {
+				if recorder, closeHistory := setupHistory(lg, cfg); recorder != nil {
+					defer closeHistory()
+					runObservers = append(runObservers, recorder)
+				}
+			}
+
+			if cfg.TracingExporter != "" {
+				closeTracing, err := setupTracing(lg, cfg)
+				if err != nil {
+					return err
+				}
+				defer closeTracing()
+			}
+
+			var stateStore state.Store
+			if cfg.StateStoreType != "" {
+				var err error
+				stateStore, err = state.New(cfg.StateStoreType, cfg.StateStorePath)
+				if err != nil {
+					return err
+				}
+				defer stateStore.Close()
+			}
+
+			var deadLetter deadletter.Writer
+			if cfg.DeadLetterPath != "" && !dryRun {
+				fw, err := deadletter.NewFileWriter(cfg.DeadLetterPath)
+				if err != nil {
+					return err
+				}
+				defer fw.Close()
+				deadLetter = fw
+			}
+
 			cs := term.NewColorScheme()
 			runner := agent.NewAgent(agent.Config{
 				ExtractorFactory:     registry.Extractors,
 				ProcessorFactory:     registry.Processors,
 				SinkFactory:          registry.Sinks,
-				Monitor:              mt,
+				StateStore:           stateStore,
+				DeadLetter:           deadLetter,
+				Observers:            runObservers,
 				Logger:               lg,
 				MaxRetries:           cfg.MaxRetries,
 				RetryInitialInterval: time.Duration(cfg.RetryInitialIntervalSeconds) * time.Second,
 				StopOnSinkError:      cfg.StopOnSinkError,
+				MaxParallelism:       cfg.MaxParallelism,
+				DryRun:               dryRun,
+				DryRunWriter:         out,
+				DrainTimeout:         time.Duration(cfg.DrainTimeoutSeconds) * time.Second,
 			})
 
 			// Monitoring system signals and creating context
 			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 			defer stop()
 
-			recipes, err := recipe.NewReader(lg, pathToConfig).Read(args[0])
+			recipes, err := recipe.NewReader(lg, pathToConfig, recipe.WithSecretResolver(setupSecrets(cfg))).Read(args[0])
 			if err != nil {
 				return err
 			}
 
 			if len(recipes) == 0 {
-				fmt.Println(cs.WarningIcon(), cs.Yellowf("No recipe found in [%s]", args[0]))
+				fmt.Fprintln(out, cs.WarningIcon(), cs.Yellowf("No recipe found in [%s]", args[0]))
 				return nil
 			}
 
-			report = append(report, []string{"Status", "Recipe", "Source", "Duration(ms)", "Records"})
+			table = append(table, []string{"Status", "Recipe", "Source", "Duration(ms)", "Records", "Skipped"})
 
 			bar := progressbar.NewOptions(len(recipes),
 				progressbar.OptionEnableColorCodes(true),
 				progressbar.OptionSetDescription("[cyan]running recipes [reset]"),
 				progressbar.OptionShowCount(),
+				progressbar.OptionSetWriter(out),
 			)
 
 			// Run recipes and collect results
 			runs := runner.RunMultiple(ctx, recipes)
 			for _, run := range runs {
-				lg.Debug("recipe details", "recipe", run.Recipe)
+				lg.Debug("recipe details", "recipe", run.Recipe.Redacted(pluginSchemas()))
 				var row []string
-				if run.Error != nil {
-					lg.Error(run.Error.Error(), "recipe")
+				// records the sinks failed to send are missing from the destinations, even if the run went on
+				if run.Error != nil || run.SinkFailureCount() > 0 {
+					if run.Error != nil {
+						lg.Error(run.Error.Error(), "recipe")
+					}
+					// the run error is the one of the first failed source, when the recipe has several
+					for _, src := range run.Sources {
+						if src.Error != nil && src.Error != run.Error {
+							lg.Error(src.Error.Error(), "recipe", run.Recipe.Name, "source", src.ID)
+						}
+					}
+					for _, sink := range run.Sinks {
+						if sink.FailureCount > 0 {
+							lg.Error("sink failed to send records", "recipe", run.Recipe.Name, "sink", sink.Name, "failure_count", sink.FailureCount)
+						}
+					}
 					failures++
-					row = append(row, cs.FailureIcon(), run.Recipe.Name, cs.Grey(run.Recipe.Source.Name), cs.Greyf("%v ms", strconv.Itoa(run.DurationInMs)), cs.Greyf(strconv.Itoa(run.RecordCount)))
+					row = append(row, cs.FailureIcon(), run.Recipe.Name, cs.Grey(run.Recipe.SourceNames()), cs.Greyf("%v ms", strconv.Itoa(run.DurationInMs)), cs.Greyf(strconv.Itoa(run.RecordCount)), cs.Greyf(strconv.Itoa(run.SkippedCount)))
 				} else {
 					success++
-					row = append(row, cs.SuccessIcon(), run.Recipe.Name, cs.Grey(run.Recipe.Source.Name), cs.Greyf("%v ms", strconv.Itoa(run.DurationInMs)), cs.Greyf(strconv.Itoa(run.RecordCount)))
+					row = append(row, cs.SuccessIcon(), run.Recipe.Name, cs.Grey(run.Recipe.SourceNames()), cs.Greyf("%v ms", strconv.Itoa(run.DurationInMs)), cs.Greyf(strconv.Itoa(run.RecordCount)), cs.Greyf(strconv.Itoa(run.SkippedCount)))
 				}
-				report = append(report, row)
+				table = append(table, row)
 				if err = bar.Add(1); err != nil {
 					return err
 				}
 			}
 
+			if reportFormat != "" {
+				if err := writeReport(reportFormat, reportFile, runs); err != nil {
+					return err
+				}
+			}
+
 			// Print the report
+			if !reportToStdout {
+				if failures > 0 {
+					fmt.Println("\nSome recipes were not successful")
+				} else {
+					fmt.Println("\nAll recipes ran successful")
+				}
+				fmt.Printf("%d failing, %d successful, and %d total\n\n", failures, success, len(recipes))
+				printer.Table(os.Stdout, table)
+			}
+
 			if failures > 0 {
-				fmt.Println("\nSome recipes were not successful")
-			} else {
-				fmt.Println("\nAll recipes ran successful")
+				// the error only sets the exit code, failing recipes are already in the table or the report
+				cmd.SilenceUsage = true
+				return newSilentError(fmt.Errorf("%d of %d recipes failed", failures, len(recipes)))
 			}
-			fmt.Printf("%d failing, %d successful, and %d total\n\n", failures, success, len(recipes))
-			printer.Table(os.Stdout, report)
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&pathToConfig, "var", "", "Path to Config file with env variables for recipe")
 	cmd.Flags().StringVarP(&configFile, "config", "c", "./meteor.yaml", "file path for agent level config")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run extractors and processors but print what sinks would receive instead of sending it")
+	cmd.Flags().StringVar(&reportFormat, "report-format", "", fmt.Sprintf("Format of the run report, one of %s", strings.Join(report.Formats, ", ")))
+	cmd.Flags().StringVar(&reportFile, "report-file", "", "Path to write the run report to, defaults to json format and stdout is used when not set")
+	cmd.Flags().IntVar(&parallelism, "max-parallelism", 0, "Max number of recipes of the same group running at the same time, overrides MAX_PARALLELISM")
 
 	return cmd
 }
+
+// writeReport writes the report of the runs in the given format to the file, or to stdout when path is empty.
+func writeReport(format, path string, runs []agent.Run) (err error) {
+	w := io.Writer(os.Stdout)
+	if path != "" {
+		f, ferr := os.Create(path)
+		if ferr != nil {
+			return errors.Wrap(ferr, "failed to create report file")
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+
+	return report.Write(w, format, report.New(runs))
+}
+
+func isReportFormat(format string) bool {
+	for _, f := range report.Formats {
+		if f == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pluginSchemas returns the config schemas of the registered plugins, telling the sensitive config values of recipes.
+func pluginSchemas() recipe.Schemas {
+	return recipe.Schemas{
+		Extractor: registry.Extractors.Schema,
+		Processor: registry.Processors.Schema,
+		Sink:      registry.Sinks.Schema,
+	}
+}
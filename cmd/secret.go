@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/odpf/meteor/config"
+	"github.com/odpf/meteor/secret"
+)
+
+// setupSecrets returns the resolver of the secret references in recipes,
+// the vault provider is only available when its address is set.
+func setupSecrets(cfg config.Config) *secret.Resolver {
+	providers := map[string]secret.Provider{
+		secret.ProviderEnv:  secret.EnvProvider{},
+		secret.ProviderFile: secret.NewFileProvider(cfg.SecretFileDir),
+	}
+	if cfg.SecretVaultAddress != "" {
+		providers[secret.ProviderVault] = secret.NewVaultProvider(cfg.SecretVaultAddress, cfg.SecretVaultToken, nil)
+	}
+
+	return secret.NewResolver(providers)
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/config"
+	"github.com/odpf/meteor/deadletter"
+	"github.com/odpf/meteor/recipe"
+	"github.com/odpf/meteor/registry"
+	"github.com/odpf/meteor/scheduler"
+	"github.com/odpf/meteor/server"
+	"github.com/odpf/meteor/state"
+	"github.com/odpf/salt/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// ServeCmd creates a command object for the "serve" action.
+func ServeCmd(lg log.Logger, observers []agent.Observer, cfg config.Config) *cobra.Command {
+	var (
+		pathToConfig   string
+		configFile     string
+		reloadInterval time.Duration
+		apiAddress     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve <path>",
+		Short: "Run recipes on their schedule",
+		Long: heredoc.Doc(`
+			Run recipes of a directory on the cron schedule set in each recipe.
+
+			A recipe is not run again while its previous run is still going on.
+			Recipe files are read again periodically, so added, changed and removed recipes
+			are picked up without a restart. Recipes without a schedule are skipped.
+
+			When an API address is set, an HTTP/JSON API lists the recipes,
+			triggers their runs, including recipes without a schedule, and returns their progress and results.`),
+		Example: heredoc.Doc(`
+			$ meteor serve _recipes/
+
+			# read recipe files for changes every minute
+			$ meteor serve _recipes/ --reload-interval 1m
+
+			# serve the HTTP API on port 8080
+			$ meteor serve _recipes/ --api-address :8080
+		`),
+		Args: cobra.ExactArgs(1),
+		Annotations: map[string]string{
+			"group:core": "true",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if reloadInterval <= 0 {
+				return fmt.Errorf("reload interval has to be positive, got %s", reloadInterval)
+			}
+			if configFile != "" {
+				var err error
+				cfg, err = config.Load(configFile)
+				if err != nil {
+					return err
+				}
+			}
+
+			if apiAddress != "" {
+				cfg.APIAddress = apiAddress
+			}
+
+			runObservers := append([]agent.Observer{}, observers...)
+			var tracker *server.Tracker
+			if cfg.APIAddress != "" {
+				tracker = server.NewTracker(0)
+				runObservers = append(runObservers, tracker)
+			}
+			if cfg.PrometheusEnabled {
+				monitor, closeMonitor, err := setupPrometheus(lg, cfg)
+				if err != nil {
+					return err
+				}
+				defer closeMonitor()
+				runObservers = append(runObservers, monitor)
+			}
+
+			if recorder, closeHistory := setupHistory(lg, cfg); recorder != nil {
+				defer closeHistory()
+				runObservers = append(runObservers, recorder)
+			}
+
+			if cfg.TracingExporter != "" {
+				closeTracing, err := setupTracing(lg, cfg)
+				if err != nil {
+					return err
+				}
+				defer closeTracing()
+			}
+
+			var stateStore state.Store
+			if cfg.StateStoreType != "" {
+				var err error
+				stateStore, err = state.New(cfg.StateStoreType, cfg.StateStorePath)
+				if err != nil {
+					return err
+				}
+				defer stateStore.Close()
+			}
+
+			var deadLetter deadletter.Writer
+			if cfg.DeadLetterPath != "" {
+				fw, err := deadletter.NewFileWriter(cfg.DeadLetterPath)
+				if err != nil {
+					return err
+				}
+				defer fw.Close()
+				deadLetter = fw
+			}
+
+			runner := agent.NewAgent(agent.Config{
+				ExtractorFactory:     registry.Extractors,
+				ProcessorFactory:     registry.Processors,
+				SinkFactory:          registry.Sinks,
+				StateStore:           stateStore,
+				DeadLetter:           deadLetter,
+				Observers:            runObservers,
+				Logger:               lg,
+				MaxRetries:           cfg.MaxRetries,
+				RetryInitialInterval: time.Duration(cfg.RetryInitialIntervalSeconds) * time.Second,
+				StopOnSinkError:      cfg.StopOnSinkError,
+				DrainTimeout:         time.Duration(cfg.DrainTimeoutSeconds) * time.Second,
+			})
+
+			reader := recipe.NewReader(lg, pathToConfig, recipe.WithSecretResolver(setupSecrets(cfg)))
+			sched := scheduler.New(runner, lg)
+			load := func() error {
+				recipes, err := reader.Read(args[0])
+				if err != nil {
+					return err
+				}
+				for _, err := range sched.Load(recipes) {
+					lg.Warn("recipe is not scheduled", "err", err.Error())
+				}
+				return nil
+			}
+			if err := load(); err != nil {
+				return err
+			}
+
+			// Monitoring system signals and creating context
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			sched.Start()
+			lg.Info("serving recipes", "path", args[0])
+
+			if tracker != nil {
+				closeAPI, err := serveAPI(lg, cfg.APIAddress, server.New(sched, tracker, lg))
+				if err != nil {
+					sched.Stop()
+					return err
+				}
+				// event streams end once the running recipes are done
+				defer closeAPI()
+			}
+
+			ticker := time.NewTicker(reloadInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					// recipes keep their previous schedule when the path cannot be read
+					if err := load(); err != nil {
+						lg.Error("failed to reload recipes", "path", args[0], "err", err.Error())
+					}
+				case <-ctx.Done():
+					lg.Info("stopping, waiting for running recipes to drain")
+					sched.Stop()
+					return nil
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&pathToConfig, "var", "", "Path to Config file with env variables for recipe")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "./meteor.yaml", "file path for agent level config")
+	cmd.Flags().StringVar(&apiAddress, "api-address", "", "Address to serve the HTTP API on, overrides API_ADDRESS")
+	cmd.Flags().DurationVar(&reloadInterval, "reload-interval", 30*time.Second, "Interval to read recipe files again for changes")
+
+	return cmd
+}
+
+// serveAPI serves the API on the address until the returned close function is called.
+func serveAPI(lg log.Logger, address string, api *server.Server) (closeFn func(), err error) {
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to listen for api")
+	}
+	srv := &http.Server{Handler: api.Handler()}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			lg.Error(err.Error(), "address", address)
+		}
+	}()
+	lg.Info("serving api", "address", address)
+
+	return func() {
+		srv.Close()
+	}, nil
+}
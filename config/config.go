@@ -13,9 +13,29 @@ type Config struct {
 	StatsdEnabled               bool   `mapstructure:"STATSD_ENABLED" default:"false"`
 	StatsdHost                  string `mapstructure:"STATSD_HOST" default:"localhost:8125"`
 	StatsdPrefix                string `mapstructure:"STATSD_PREFIX" default:"meteor"`
+	PrometheusEnabled           bool   `mapstructure:"PROMETHEUS_ENABLED" default:"false"`
+	PrometheusAddress           string `mapstructure:"PROMETHEUS_ADDRESS" default:":9464"`
+	PrometheusNamespace         string `mapstructure:"PROMETHEUS_NAMESPACE" default:"meteor"`
+	PrometheusPushgatewayURL    string `mapstructure:"PROMETHEUS_PUSHGATEWAY_URL" default:""`
+	PrometheusJob               string `mapstructure:"PROMETHEUS_JOB" default:"meteor"`
+	TracingExporter             string `mapstructure:"TRACING_EXPORTER" default:""`
+	TracingOTLPEndpoint         string `mapstructure:"TRACING_OTLP_ENDPOINT" default:"localhost:4317"`
+	TracingOTLPInsecure         bool   `mapstructure:"TRACING_OTLP_INSECURE" default:"false"`
+	TracingFilePath             string `mapstructure:"TRACING_FILE_PATH" default:"./meteor-traces.json"`
 	MaxRetries                  int    `mapstructure:"MAX_RETRIES" default:"5"`
 	RetryInitialIntervalSeconds int    `mapstructure:"RETRY_INITIAL_INTERVAL_SECONDS" default:"5"`
 	StopOnSinkError             bool   `mapstructure:"STOP_ON_SINK_ERROR" default:"false"`
+	MaxParallelism              int    `mapstructure:"MAX_PARALLELISM" default:"0"`
+	DrainTimeoutSeconds         int    `mapstructure:"DRAIN_TIMEOUT_SECONDS" default:"30"`
+	StateStoreType              string `mapstructure:"STATE_STORE_TYPE" default:""`
+	StateStorePath              string `mapstructure:"STATE_STORE_PATH" default:"./meteor-state.json"`
+	DeadLetterPath              string `mapstructure:"DEAD_LETTER_PATH" default:""`
+	APIAddress                  string `mapstructure:"API_ADDRESS" default:""`
+	HistoryEnabled              bool   `mapstructure:"HISTORY_ENABLED" default:"false"`
+	HistoryPath                 string `mapstructure:"HISTORY_PATH" default:""`
+	SecretFileDir               string `mapstructure:"SECRET_FILE_DIR" default:""`
+	SecretVaultAddress          string `mapstructure:"SECRET_VAULT_ADDRESS" default:""`
+	SecretVaultToken            string `mapstructure:"SECRET_VAULT_TOKEN" default:""`
 }
 
 func Load(configFile string) (cfg Config, err error) {
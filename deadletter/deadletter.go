@@ -0,0 +1,114 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/odpf/meteor/models"
+	_ "github.com/odpf/meteor/models/odpf/assets/v1beta1" // used to register asset types for decoding records
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Entry is a batch of records a sink permanently rejected,
+// or a record diverted by a processor failing on it.
+// SinkIndex is the index of the sink in its recipe, telling apart sinks with the same name.
+type Entry struct {
+	Timestamp time.Time
+	Recipe    string
+	Sink      string
+	SinkIndex int
+	Processor string
+	Error     string
+	Attempts  int
+	Records   []models.Record
+}
+
+// Writer is a destination for entries.
+type Writer interface {
+	Write(ctx context.Context, entry Entry) error
+	Close() error
+}
+
+// entryJSON is the encoded form of an Entry, records are encoded as
+// protobuf Any so they can be decoded back to their asset type.
+type entryJSON struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Recipe    string            `json:"recipe"`
+	Sink      string            `json:"sink,omitempty"`
+	SinkIndex int               `json:"sink_index,omitempty"`
+	Processor string            `json:"processor,omitempty"`
+	Error     string            `json:"error"`
+	Attempts  int               `json:"attempts"`
+	Records   []json.RawMessage `json:"records"`
+}
+
+// MarshalJSON encodes the entry with its records.
+func (e Entry) MarshalJSON() ([]byte, error) {
+	ej := entryJSON{
+		Timestamp: e.Timestamp,
+		Recipe:    e.Recipe,
+		Sink:      e.Sink,
+		SinkIndex: e.SinkIndex,
+		Processor: e.Processor,
+		Error:     e.Error,
+		Attempts:  e.Attempts,
+	}
+	for _, record := range e.Records {
+		msg, ok := record.Data().(proto.Message)
+		if !ok {
+			return nil, errors.Errorf("record of type %T is not a protobuf message", record.Data())
+		}
+		wrapped, err := anypb.New(msg)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to wrap record")
+		}
+		raw, err := protojson.Marshal(wrapped)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode record")
+		}
+		ej.Records = append(ej.Records, raw)
+	}
+
+	return json.Marshal(ej)
+}
+
+// UnmarshalJSON decodes the entry and its records.
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	var ej entryJSON
+	if err := json.Unmarshal(data, &ej); err != nil {
+		return err
+	}
+
+	records := make([]models.Record, 0, len(ej.Records))
+	for _, raw := range ej.Records {
+		var wrapped anypb.Any
+		if err := protojson.Unmarshal(raw, &wrapped); err != nil {
+			return errors.Wrap(err, "failed to decode record")
+		}
+		msg, err := wrapped.UnmarshalNew()
+		if err != nil {
+			return errors.Wrap(err, "failed to decode record")
+		}
+		metadata, ok := msg.(models.Metadata)
+		if !ok {
+			return errors.Errorf("record of type %s is not an asset", wrapped.TypeUrl)
+		}
+		records = append(records, models.NewRecord(metadata))
+	}
+
+	*e = Entry{
+		Timestamp: ej.Timestamp,
+		Recipe:    ej.Recipe,
+		Sink:      ej.Sink,
+		SinkIndex: ej.SinkIndex,
+		Processor: ej.Processor,
+		Error:     ej.Error,
+		Attempts:  ej.Attempts,
+		Records:   records,
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package deadletter
+
+import "context"
+
+// Discard is a Writer dropping every entry, used when nothing should be written such as on dry runs.
+var Discard Writer = discard{}
+
+type discard struct{}
+
+func (discard) Write(ctx context.Context, entry Entry) error {
+	return nil
+}
+
+func (discard) Close() error {
+	return nil
+}
@@ -0,0 +1,82 @@
+package deadletter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// maxLineSize is the max size of an entry read from a file, a batch of records can be large.
+const maxLineSize = 64 * 1024 * 1024
+
+// FileWriter appends entries to a NDJSON file, one entry per line.
+type FileWriter struct {
+	file *os.File
+	mu   sync.Mutex
+}
+
+// NewFileWriter opens the file on path for appending, creating it if needed.
+func NewFileWriter(path string) (*FileWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, errors.Wrap(err, "failed to create dead letter directory")
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open dead letter file")
+	}
+
+	return &FileWriter{file: file}, nil
+}
+
+// Write appends the entry to the file.
+func (w *FileWriter) Write(_ context.Context, entry Entry) error {
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode dead letter entry")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err = w.file.Write(append(bytes, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write dead letter entry")
+	}
+
+	return nil
+}
+
+// Close closes the file.
+func (w *FileWriter) Close() error {
+	return w.file.Close()
+}
+
+// ReadFile returns all entries of a NDJSON file written by FileWriter.
+func ReadFile(path string) (entries []Entry, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open dead letter file")
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode dead letter entry on line %d", line)
+		}
+		entries = append(entries, entry)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read dead letter file")
+	}
+
+	return entries, nil
+}
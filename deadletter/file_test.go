@@ -0,0 +1,66 @@
+package deadletter_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/odpf/meteor/deadletter"
+	"github.com/odpf/meteor/models"
+	commonv1beta1 "github.com/odpf/meteor/models/odpf/assets/common/v1beta1"
+	assetsv1beta1 "github.com/odpf/meteor/models/odpf/assets/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFileWriter(t *testing.T) {
+	t.Run("should read back entries written to the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dead-letter.ndjson")
+		entry := deadletter.Entry{
+			Timestamp: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+			Recipe:    "sample",
+			Sink:      "compass",
+			Error:     "compass returns 400: invalid asset",
+			Attempts:  1,
+			Records: []models.Record{
+				models.NewRecord(&assetsv1beta1.Table{
+					Resource: &commonv1beta1.Resource{Urn: "bigquery::project/dataset/table", Name: "table"},
+				}),
+				models.NewRecord(&assetsv1beta1.Dashboard{
+					Resource: &commonv1beta1.Resource{Urn: "metabase::my-metabase/dashboard/1", Name: "dashboard"},
+				}),
+			},
+		}
+
+		writer, err := deadletter.NewFileWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.Write(context.TODO(), entry))
+		require.NoError(t, writer.Write(context.TODO(), entry))
+		require.NoError(t, writer.Close())
+
+		entries, err := deadletter.ReadFile(path)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+
+		actual := entries[0]
+		assert.Equal(t, entry.Timestamp, actual.Timestamp)
+		assert.Equal(t, entry.Recipe, actual.Recipe)
+		assert.Equal(t, entry.Sink, actual.Sink)
+		assert.Equal(t, entry.Error, actual.Error)
+		assert.Equal(t, entry.Attempts, actual.Attempts)
+		require.Len(t, actual.Records, len(entry.Records))
+		for i := range entry.Records {
+			assert.True(t, proto.Equal(
+				entry.Records[i].Data().(proto.Message),
+				actual.Records[i].Data().(proto.Message),
+			))
+		}
+	})
+
+	t.Run("should return error if file does not exist", func(t *testing.T) {
+		_, err := deadletter.ReadFile(filepath.Join(t.TempDir(), "missing.ndjson"))
+		assert.Error(t, err)
+	})
+}
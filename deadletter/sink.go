@@ -0,0 +1,71 @@
+package deadletter
+
+import (
+	"context"
+	"time"
+
+	"github.com/odpf/meteor/models"
+	"github.com/odpf/meteor/plugins"
+	"github.com/odpf/meteor/utils"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// PropertyEntry is the custom property of the records sent to a dead letter sink,
+// holding the timestamp, recipe, sink, processor, error and attempts of their entry.
+const PropertyEntry = "dead_letter"
+
+// SinkWriter sends the records of entries to a sink.
+type SinkWriter struct {
+	sink plugins.Syncer
+}
+
+// NewSinkWriter returns a Writer for an initiated sink.
+func NewSinkWriter(sink plugins.Syncer) *SinkWriter {
+	return &SinkWriter{sink: sink}
+}
+
+// Write sends the records of the entry to the sink, each with the entry in its PropertyEntry custom property.
+func (w *SinkWriter) Write(ctx context.Context, entry Entry) error {
+	records := make([]models.Record, 0, len(entry.Records))
+	for _, record := range entry.Records {
+		withEntry, err := recordWithEntry(record, entry)
+		if err != nil {
+			return err
+		}
+		records = append(records, withEntry)
+	}
+
+	return w.sink.Sink(ctx, records)
+}
+
+// Close closes the sink.
+func (w *SinkWriter) Close() error {
+	return w.sink.Close()
+}
+
+// recordWithEntry returns a copy of the record with the entry in its custom properties,
+// the record itself is left as it is since other sinks of the recipe may hold it.
+func recordWithEntry(record models.Record, entry Entry) (models.Record, error) {
+	msg, ok := record.Data().(proto.Message)
+	if !ok {
+		return record, errors.Errorf("record of type %T is not a protobuf message", record.Data())
+	}
+	data := proto.Clone(msg).(models.Metadata)
+
+	props := utils.GetCustomProperties(data)
+	props[PropertyEntry] = map[string]interface{}{
+		"timestamp": entry.Timestamp.UTC().Format(time.RFC3339Nano),
+		"recipe":    entry.Recipe,
+		"sink":      entry.Sink,
+		"processor": entry.Processor,
+		"error":     entry.Error,
+		"attempts":  entry.Attempts,
+	}
+	data, err := utils.SetCustomProperties(data, props)
+	if err != nil {
+		return record, errors.Wrap(err, "failed to set dead letter entry of record")
+	}
+
+	return models.NewRecord(data), nil
+}
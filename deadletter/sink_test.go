@@ -0,0 +1,55 @@
+package deadletter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odpf/meteor/deadletter"
+	"github.com/odpf/meteor/models"
+	commonv1beta1 "github.com/odpf/meteor/models/odpf/assets/common/v1beta1"
+	assetsv1beta1 "github.com/odpf/meteor/models/odpf/assets/v1beta1"
+	"github.com/odpf/meteor/test/mocks"
+	"github.com/odpf/meteor/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSinkWriter(t *testing.T) {
+	t.Run("should send records with their entry to the sink", func(t *testing.T) {
+		table := &assetsv1beta1.Table{
+			Resource: &commonv1beta1.Resource{Urn: "bigquery::project/dataset/table", Name: "table"},
+		}
+		entry := deadletter.Entry{
+			Timestamp: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+			Recipe:    "sample",
+			Sink:      "compass",
+			Error:     "compass returns 400: invalid asset",
+			Attempts:  3,
+			Records:   []models.Record{models.NewRecord(table)},
+		}
+
+		var sent []models.Record
+		sink := mocks.NewSink()
+		sink.On("Sink", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			sent = args.Get(1).([]models.Record)
+		}).Return(nil)
+		defer sink.AssertExpectations(t)
+
+		writer := deadletter.NewSinkWriter(sink)
+		require.NoError(t, writer.Write(context.TODO(), entry))
+
+		require.Len(t, sent, 1)
+		assert.Equal(t, "bigquery::project/dataset/table", sent[0].Data().GetResource().Urn)
+		assert.Equal(t, map[string]interface{}{
+			"timestamp": "2022-01-02T03:04:05Z",
+			"recipe":    "sample",
+			"sink":      "compass",
+			"processor": "",
+			"error":     "compass returns 400: invalid asset",
+			"attempts":  float64(3),
+		}, utils.GetCustomProperties(sent[0].Data())[deadletter.PropertyEntry])
+		assert.Nil(t, table.Properties, "rejected record should not be changed")
+	})
+}
@@ -0,0 +1,296 @@
+// Package diff compares the assets of two extraction outputs written by the file sink in ndjson format.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kinds of change of an asset
+const (
+	KindColumnAdded              = "column_added"
+	KindColumnDropped            = "column_dropped"
+	KindColumnTypeChanged        = "column_type_changed"
+	KindColumnNullabilityChanged = "column_nullability_changed"
+	KindOwnerAdded               = "owner_added"
+	KindOwnerRemoved             = "owner_removed"
+	KindUpstreamAdded            = "upstream_added"
+	KindUpstreamRemoved          = "upstream_removed"
+	KindDownstreamAdded          = "downstream_added"
+	KindDownstreamRemoved        = "downstream_removed"
+	KindFieldChanged             = "field_changed"
+)
+
+// Asset is an asset decoded from its json encoding by the file sink.
+type Asset map[string]interface{}
+
+// URN returns the urn of the resource of the asset.
+func (a Asset) URN() string {
+	return a.resourceField("urn")
+}
+
+func (a Asset) summary() AssetSummary {
+	return AssetSummary{
+		URN:  a.URN(),
+		Type: a.resourceField("type"),
+		Name: a.resourceField("name"),
+	}
+}
+
+func (a Asset) resourceField(name string) string {
+	resource, _ := a["resource"].(map[string]interface{})
+	value, _ := resource[name].(string)
+	return value
+}
+
+// Result contains the assets added, removed and changed between two extraction outputs, ordered by URN.
+type Result struct {
+	Added   []AssetSummary `json:"added"`
+	Removed []AssetSummary `json:"removed"`
+	Changed []AssetChanges `json:"changed"`
+}
+
+// Empty returns true when both outputs have the same assets.
+func (r Result) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// AssetSummary identifies an asset.
+type AssetSummary struct {
+	URN  string `json:"urn"`
+	Type string `json:"type,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// AssetChanges contains the changes of an asset present in both outputs.
+type AssetChanges struct {
+	AssetSummary
+	Changes []Change `json:"changes"`
+}
+
+// Change is a change of an asset. Path is the dotted path of the changed field,
+// items of columns, owners and lineage are addressed by their key in brackets, such as schema.columns[id].data_type.
+// Old is not set for additions and New is not set for removals.
+type Change struct {
+	Kind string      `json:"kind"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// Compare returns the differences between the old and the new assets, by URN.
+// Fields at the dotted paths of ignoreFields are left out of the comparison.
+func Compare(oldAssets, newAssets map[string]Asset, ignoreFields []string) (result Result) {
+	for urn, asset := range newAssets {
+		if _, ok := oldAssets[urn]; !ok {
+			result.Added = append(result.Added, asset.summary())
+		}
+	}
+	for urn, oldAsset := range oldAssets {
+		newAsset, ok := newAssets[urn]
+		if !ok {
+			result.Removed = append(result.Removed, oldAsset.summary())
+			continue
+		}
+		if changes := compareAssets(oldAsset, newAsset, ignoreFields); len(changes) > 0 {
+			result.Changed = append(result.Changed, AssetChanges{
+				AssetSummary: newAsset.summary(),
+				Changes:      changes,
+			})
+		}
+	}
+
+	sortSummaries(result.Added)
+	sortSummaries(result.Removed)
+	sort.Slice(result.Changed, func(i, j int) bool {
+		return result.Changed[i].URN < result.Changed[j].URN
+	})
+
+	return result
+}
+
+// compareAssets returns the changes from old to new, columns, owners and lineage edges are compared by their key,
+// other fields by their path.
+func compareAssets(oldAsset, newAsset Asset, ignoreFields []string) (changes []Change) {
+	oldFields, newFields := clone(oldAsset), clone(newAsset)
+	for _, field := range ignoreFields {
+		path := strings.Split(field, ".")
+		removeField(oldFields, path)
+		removeField(newFields, path)
+	}
+
+	oldColumns, newColumns := takeItems(oldFields, "schema", "columns", "name"), takeItems(newFields, "schema", "columns", "name")
+	changes = append(changes, compareItems("schema.columns", oldColumns, newColumns, KindColumnAdded, KindColumnDropped, compareColumns)...)
+
+	oldOwners, newOwners := takeItems(oldFields, "ownership", "owners", "urn", "email", "name"), takeItems(newFields, "ownership", "owners", "urn", "email", "name")
+	changes = append(changes, compareItems("ownership.owners", oldOwners, newOwners, KindOwnerAdded, KindOwnerRemoved, compareFields)...)
+
+	oldUpstreams, newUpstreams := takeItems(oldFields, "lineage", "upstreams", "urn"), takeItems(newFields, "lineage", "upstreams", "urn")
+	changes = append(changes, compareItems("lineage.upstreams", oldUpstreams, newUpstreams, KindUpstreamAdded, KindUpstreamRemoved, compareFields)...)
+
+	oldDownstreams, newDownstreams := takeItems(oldFields, "lineage", "downstreams", "urn"), takeItems(newFields, "lineage", "downstreams", "urn")
+	changes = append(changes, compareItems("lineage.downstreams", oldDownstreams, newDownstreams, KindDownstreamAdded, KindDownstreamRemoved, compareFields)...)
+
+	return append(changes, compareFields("", oldFields, newFields)...)
+}
+
+// compareColumns returns the changes of a column, its type and nullability changes have their own kind.
+func compareColumns(path string, oldColumn, newColumn map[string]interface{}) (changes []Change) {
+	if oldType, newType := oldColumn["data_type"], newColumn["data_type"]; !reflect.DeepEqual(oldType, newType) {
+		changes = append(changes, Change{Kind: KindColumnTypeChanged, Path: path + ".data_type", Old: oldType, New: newType})
+	}
+	// the file sink leaves out false values
+	if oldNullable, newNullable := oldColumn["is_nullable"] == true, newColumn["is_nullable"] == true; oldNullable != newNullable {
+		changes = append(changes, Change{Kind: KindColumnNullabilityChanged, Path: path + ".is_nullable", Old: oldNullable, New: newNullable})
+	}
+
+	oldColumn, newColumn = clone(oldColumn), clone(newColumn)
+	for _, field := range []string{"data_type", "is_nullable"} {
+		delete(oldColumn, field)
+		delete(newColumn, field)
+	}
+
+	return append(changes, compareFields(path, oldColumn, newColumn)...)
+}
+
+// compareItems returns the items added and removed, and the changes of the items in both, ordered by key.
+func compareItems(path string, oldItems, newItems map[string]map[string]interface{}, addedKind, removedKind string,
+	compare func(path string, oldItem, newItem map[string]interface{}) []Change) (changes []Change) {
+	for _, key := range sortedKeys(oldItems, newItems) {
+		itemPath := fmt.Sprintf("%s[%s]", path, key)
+		oldItem, inOld := oldItems[key]
+		newItem, inNew := newItems[key]
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Kind: addedKind, Path: itemPath, New: newItem})
+		case !inNew:
+			changes = append(changes, Change{Kind: removedKind, Path: itemPath, Old: oldItem})
+		default:
+			changes = append(changes, compare(itemPath, oldItem, newItem)...)
+		}
+	}
+
+	return changes
+}
+
+// compareFields returns a change for each leaf field that differs, ordered by path. Lists are compared as a whole.
+func compareFields(path string, oldFields, newFields map[string]interface{}) (changes []Change) {
+	oldLeaves, newLeaves := make(map[string]interface{}), make(map[string]interface{})
+	flatten(path, oldFields, oldLeaves)
+	flatten(path, newFields, newLeaves)
+
+	for _, leaf := range sortedKeys(oldLeaves, newLeaves) {
+		if oldValue, newValue := oldLeaves[leaf], newLeaves[leaf]; !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, Change{Kind: KindFieldChanged, Path: leaf, Old: oldValue, New: newValue})
+		}
+	}
+
+	return changes
+}
+
+func flatten(path string, fields map[string]interface{}, leaves map[string]interface{}) {
+	for name, value := range fields {
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flatten(fieldPath, nested, leaves)
+			continue
+		}
+		leaves[fieldPath] = value
+	}
+}
+
+// takeItems removes the list at parent.field from fields and returns its items by key,
+// the key of an item is the value of the first of keyFields it has.
+func takeItems(fields map[string]interface{}, parent, field string, keyFields ...string) map[string]map[string]interface{} {
+	parentFields, ok := fields[parent].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	list, _ := parentFields[field].([]interface{})
+	delete(parentFields, field)
+	if len(parentFields) == 0 {
+		delete(fields, parent)
+	}
+
+	items := make(map[string]map[string]interface{})
+	for i, value := range list {
+		item, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key := fmt.Sprintf("#%d", i)
+		for _, keyField := range keyFields {
+			if k, ok := item[keyField].(string); ok && k != "" {
+				key = k
+				break
+			}
+		}
+		items[key] = item
+	}
+
+	return items
+}
+
+// removeField removes the field at path, it is removed from every item of the lists on the path.
+func removeField(fields map[string]interface{}, path []string) {
+	value, ok := fields[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		delete(fields, path[0])
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		removeField(v, path[1:])
+	case []interface{}:
+		for _, item := range v {
+			if nested, ok := item.(map[string]interface{}); ok {
+				removeField(nested, path[1:])
+			}
+		}
+	}
+}
+
+// clone returns a deep copy of the decoded json fields.
+func clone(fields map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return fields
+	}
+	var cloned map[string]interface{}
+	if err := json.Unmarshal(data, &cloned); err != nil {
+		return fields
+	}
+
+	return cloned
+}
+
+func sortedKeys(maps ...interface{}) (keys []string) {
+	seen := make(map[string]bool)
+	for _, m := range maps {
+		for _, key := range reflect.ValueOf(m).MapKeys() {
+			if k := key.String(); !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortSummaries(summaries []AssetSummary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].URN < summaries[j].URN
+	})
+}
@@ -0,0 +1,138 @@
+package diff_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/odpf/meteor/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ignoreFields = []string{"event", "profile", "preview"}
+
+func TestReadFile(t *testing.T) {
+	t.Run("should read assets by urn and remove the ones with a tombstone", func(t *testing.T) {
+		assets, err := diff.ReadFile("./testdata/new.ndjson")
+		require.NoError(t, err)
+
+		assert.Len(t, assets, 3)
+		assert.Contains(t, assets, "bigquery::project/dataset/payments")
+		assert.NotContains(t, assets, "bigquery::project/dataset/sessions")
+	})
+
+	t.Run("should return error for asset without urn", func(t *testing.T) {
+		_, err := diff.ReadFile("./testdata/no-urn.ndjson")
+		assert.EqualError(t, err, "asset on line 1 has no urn")
+	})
+}
+
+func TestCompare(t *testing.T) {
+	oldAssets, err := diff.ReadFile("./testdata/old.ndjson")
+	require.NoError(t, err)
+	newAssets, err := diff.ReadFile("./testdata/new.ndjson")
+	require.NoError(t, err)
+
+	t.Run("should return assets added, removed and changed", func(t *testing.T) {
+		result := diff.Compare(oldAssets, newAssets, ignoreFields)
+
+		assert.Equal(t, []diff.AssetSummary{{URN: "bigquery::project/dataset/payments", Type: "table", Name: "payments"}}, result.Added)
+		assert.Equal(t, []diff.AssetSummary{{URN: "bigquery::project/dataset/sessions", Type: "table", Name: "sessions"}}, result.Removed)
+		require.Len(t, result.Changed, 1)
+		assert.Equal(t, "bigquery::project/dataset/orders", result.Changed[0].URN)
+
+		var kinds, paths []string
+		for _, c := range result.Changed[0].Changes {
+			kinds = append(kinds, c.Kind)
+			paths = append(paths, c.Path)
+		}
+		assert.Equal(t, []string{
+			diff.KindColumnAdded,
+			diff.KindColumnDropped,
+			diff.KindColumnTypeChanged,
+			diff.KindColumnNullabilityChanged,
+			diff.KindOwnerAdded,
+			diff.KindOwnerRemoved,
+			diff.KindDownstreamAdded,
+			diff.KindFieldChanged,
+		}, kinds)
+		assert.Equal(t, []string{
+			"schema.columns[discount]",
+			"schema.columns[legacy_id]",
+			"schema.columns[price].data_type",
+			"schema.columns[price].is_nullable",
+			"ownership.owners[jane@odpf.io]",
+			"ownership.owners[john@odpf.io]",
+			"lineage.downstreams[metabase::dashboard/1]",
+			"resource.description",
+		}, paths)
+		assert.Equal(t, diff.Change{
+			Kind: diff.KindColumnTypeChanged,
+			Path: "schema.columns[price].data_type",
+			Old:  "INT64",
+			New:  "NUMERIC",
+		}, result.Changed[0].Changes[2])
+	})
+
+	t.Run("should compare ignored fields when not ignored", func(t *testing.T) {
+		result := diff.Compare(oldAssets, newAssets, nil)
+
+		require.Len(t, result.Changed, 1)
+		assert.Contains(t, result.Changed[0].Changes, diff.Change{
+			Kind: diff.KindFieldChanged,
+			Path: "profile.total_rows",
+			Old:  float64(10),
+			New:  float64(20),
+		})
+	})
+
+	t.Run("should return empty result for same assets", func(t *testing.T) {
+		result := diff.Compare(oldAssets, oldAssets, ignoreFields)
+		assert.True(t, result.Empty())
+	})
+}
+
+func TestWrite(t *testing.T) {
+	oldAssets, err := diff.ReadFile("./testdata/old.ndjson")
+	require.NoError(t, err)
+	newAssets, err := diff.ReadFile("./testdata/new.ndjson")
+	require.NoError(t, err)
+	result := diff.Compare(oldAssets, newAssets, ignoreFields)
+
+	t.Run("should return error for unknown format", func(t *testing.T) {
+		err := diff.Write(new(bytes.Buffer), "xml", result)
+		assert.EqualError(t, err, "unknown diff format \"xml\", expected one of text, json")
+	})
+
+	t.Run("should write text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, diff.Write(&buf, diff.FormatText, result))
+
+		assert.Equal(t, `+ bigquery::project/dataset/payments (table)
+- bigquery::project/dataset/sessions (table)
+~ bigquery::project/dataset/orders (table)
+    + schema.columns[discount]
+    - schema.columns[legacy_id]
+    ~ schema.columns[price].data_type: "INT64" -> "NUMERIC"
+    ~ schema.columns[price].is_nullable: false -> true
+    + ownership.owners[jane@odpf.io]
+    - ownership.owners[john@odpf.io]
+    + lineage.downstreams[metabase::dashboard/1]
+    ~ resource.description: "orders" -> "all orders"
+
+1 added, 1 removed and 1 changed
+`, buf.String())
+	})
+
+	t.Run("should write json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, diff.Write(&buf, diff.FormatJSON, result))
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		changed := decoded["changed"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "bigquery::project/dataset/orders", changed["urn"])
+		assert.Len(t, changed["changes"], 8)
+	})
+}
@@ -0,0 +1,57 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// eventActionDelete is the action of the event of a tombstone record
+const eventActionDelete = "delete"
+
+// maxLineSize is the max size of an asset read from a file, a table with many columns can be large.
+const maxLineSize = 64 * 1024 * 1024
+
+// ReadFile returns the assets of a ndjson file written by the file sink, by URN.
+// An asset written more than once is the last one written, and a tombstone record removes its asset.
+func ReadFile(path string) (assets map[string]Asset, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open file")
+	}
+	defer file.Close()
+
+	assets = make(map[string]Asset)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var asset Asset
+		if err = json.Unmarshal(scanner.Bytes(), &asset); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode asset on line %d", line)
+		}
+		urn := asset.URN()
+		if urn == "" {
+			return nil, errors.Errorf("asset on line %d has no urn", line)
+		}
+		if isTombstone(asset) {
+			delete(assets, urn)
+			continue
+		}
+		assets[urn] = asset
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read file")
+	}
+
+	return assets, nil
+}
+
+func isTombstone(asset Asset) bool {
+	event, _ := asset["event"].(map[string]interface{})
+	return event["action"] == eventActionDelete
+}
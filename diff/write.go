@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Formats are the supported output formats
+var Formats = []string{FormatText, FormatJSON}
+
+// Write encodes the result to w in the given format.
+func Write(w io.Writer, format string, r Result) error {
+	switch format {
+	case FormatText:
+		return writeText(w, r)
+	case FormatJSON:
+		return writeJSON(w, r)
+	default:
+		return fmt.Errorf("unknown diff format \"%s\", expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+func writeJSON(w io.Writer, r Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// writeText writes a line per asset added or removed, and per change of the changed assets.
+func writeText(w io.Writer, r Result) error {
+	var b strings.Builder
+	for _, asset := range r.Added {
+		fmt.Fprintf(&b, "+ %s\n", assetTitle(asset))
+	}
+	for _, asset := range r.Removed {
+		fmt.Fprintf(&b, "- %s\n", assetTitle(asset))
+	}
+	for _, asset := range r.Changed {
+		fmt.Fprintf(&b, "~ %s\n", assetTitle(asset.AssetSummary))
+		for _, change := range asset.Changes {
+			fmt.Fprintf(&b, "    %s\n", changeLine(change))
+		}
+	}
+	fmt.Fprintf(&b, "\n%d added, %d removed and %d changed\n", len(r.Added), len(r.Removed), len(r.Changed))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func assetTitle(asset AssetSummary) string {
+	if asset.Type == "" {
+		return asset.URN
+	}
+	return fmt.Sprintf("%s (%s)", asset.URN, asset.Type)
+}
+
+// changeLine renders the change, items of columns, owners and lineage added or removed are rendered by their path only.
+func changeLine(c Change) string {
+	switch c.Kind {
+	case KindColumnAdded, KindOwnerAdded, KindUpstreamAdded, KindDownstreamAdded:
+		return "+ " + c.Path
+	case KindColumnDropped, KindOwnerRemoved, KindUpstreamRemoved, KindDownstreamRemoved:
+		return "- " + c.Path
+	}
+
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("+ %s: %s", c.Path, formatValue(c.New))
+	case c.New == nil:
+		return fmt.Sprintf("- %s: %s", c.Path, formatValue(c.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, formatValue(c.Old), formatValue(c.New))
+	}
+}
+
+func formatValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
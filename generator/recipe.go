@@ -1,13 +1,17 @@
 package generator
 
 import (
+	"bytes"
 	"embed"
 	"os"
 	"strings"
 	"text/template"
 
+	"github.com/odpf/meteor/plugins"
 	"github.com/odpf/meteor/registry"
+	"github.com/odpf/meteor/secret"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 )
 
 //go:embed recipe.yaml
@@ -41,7 +45,7 @@ func Recipe(name string, source string, sinks []string, processors []string) (er
 		if err != nil {
 			return errors.Wrap(err, "failed to provide extractor information")
 		}
-		tem.Source[source] = sourceInfo.SampleConfig
+		tem.Source[source] = maskSampleConfig(source, sourceInfo)
 	}
 	if len(sinks) > 0 {
 		tem.Sinks = make(map[string]string)
@@ -50,7 +54,7 @@ func Recipe(name string, source string, sinks []string, processors []string) (er
 			if err != nil {
 				return errors.Wrap(err, "failed to provide sink information")
 			}
-			tem.Sinks[sink] = info.SampleConfig
+			tem.Sinks[sink] = maskSampleConfig(sink, info)
 		}
 	}
 	if len(processors) > 0 {
@@ -60,7 +64,7 @@ func Recipe(name string, source string, sinks []string, processors []string) (er
 			if err != nil {
 				return errors.Wrap(err, "failed to provide processor information")
 			}
-			tem.Processors[procc] = info.SampleConfig
+			tem.Processors[procc] = maskSampleConfig(procc, info)
 		}
 	}
 
@@ -73,6 +77,31 @@ func Recipe(name string, source string, sinks []string, processors []string) (er
 	return nil
 }
 
+// maskSampleConfig returns the sample config of a plugin with its sensitive values replaced by references
+// to environment variables, so generated recipes do not hold credentials.
+// Sample configs that cannot be parsed are returned as they are.
+func maskSampleConfig(name string, info plugins.Info) string {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(info.SampleConfig), &doc); err != nil || len(doc.Content) == 0 {
+		return info.SampleConfig
+	}
+	masked := plugins.MaskConfigNode(doc.Content[0], info.ConfigSchema, func(key string) string {
+		return secret.EnvReference(name, key)
+	})
+	if !masked {
+		return info.SampleConfig
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc.Content[0]); err != nil {
+		return info.SampleConfig
+	}
+	// sample configs start on the line after the config key
+	return "\n" + strings.TrimSuffix(buf.String(), "\n")
+}
+
 func indent(spaces int, v string) string {
 	pad := strings.Repeat(" ", spaces)
 	return pad + strings.Replace(v, "\n", "\n"+pad, -1)
@@ -0,0 +1,212 @@
+package history_test
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/history"
+	"github.com/odpf/meteor/recipe"
+	"github.com/odpf/meteor/test/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	t.Run("should list entries of a recipe, the latest first", func(t *testing.T) {
+		store := newStore(t)
+		startedAt := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+		for i, name := range []string{"recipe-a", "recipe-b", "recipe-a"} {
+			err := store.Add(history.Entry{
+				Recipe:       name,
+				Source:       "mysql",
+				StartedAt:    startedAt,
+				FinishedAt:   startedAt.Add(time.Second),
+				DurationInMs: 1000,
+				RecordCount:  i,
+				Sinks:        []agent.SinkRun{{Name: "console", SuccessCount: i}},
+				Success:      true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		entries, err := store.List("recipe-a", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []history.Entry{
+			{
+				ID:           3,
+				Recipe:       "recipe-a",
+				Source:       "mysql",
+				StartedAt:    startedAt,
+				FinishedAt:   startedAt.Add(time.Second),
+				DurationInMs: 1000,
+				RecordCount:  2,
+				Sinks:        []agent.SinkRun{{Name: "console", SuccessCount: 2}},
+				Success:      true,
+			},
+			{
+				ID:           1,
+				Recipe:       "recipe-a",
+				Source:       "mysql",
+				StartedAt:    startedAt,
+				FinishedAt:   startedAt.Add(time.Second),
+				DurationInMs: 1000,
+				RecordCount:  0,
+				Sinks:        []agent.SinkRun{{Name: "console", SuccessCount: 0}},
+				Success:      true,
+			},
+		}, entries)
+	})
+
+	t.Run("should limit entries of all recipes", func(t *testing.T) {
+		store := newStore(t)
+		for _, name := range []string{"recipe-a", "recipe-b", "recipe-c"} {
+			if err := store.Add(history.Entry{Recipe: name}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		entries, err := store.List("", 2)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+		assert.Equal(t, "recipe-c", entries[0].Recipe)
+		assert.Equal(t, "recipe-b", entries[1].Recipe)
+	})
+
+	t.Run("should add entries of runs ending together", func(t *testing.T) {
+		store := newStore(t)
+		var wg sync.WaitGroup
+		errs := make(chan error, 100)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 5; j++ {
+					errs <- store.Add(history.Entry{Recipe: "recipe-a"})
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+		entries, err := store.List("recipe-a", 0)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 100)
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	t.Run("should add ended runs to the store", func(t *testing.T) {
+		store := newStore(t)
+		rcp := recipe.Recipe{Name: "recipe-a", Source: recipe.PluginRecipe{Name: "mysql"}}
+
+		recorder := history.NewRecorder(store, utils.Logger)
+		recorder.OnRunStart(rcp)
+		recorder.OnRunEnd(agent.Run{
+			Recipe:       rcp,
+			Error:        errors.New("failed to setup extractor"),
+			DurationInMs: 1500,
+			RecordCount:  3,
+			Sinks:        []agent.SinkRun{{Name: "console", FailureCount: 3}},
+		})
+
+		entries, err := store.List("", 0)
+		assert.NoError(t, err)
+		if assert.Len(t, entries, 1) {
+			entry := entries[0]
+			assert.Equal(t, "recipe-a", entry.Recipe)
+			assert.Equal(t, "mysql", entry.Source)
+			assert.Equal(t, 1500, entry.DurationInMs)
+			assert.Equal(t, 3, entry.RecordCount)
+			assert.Equal(t, []agent.SinkRun{{Name: "console", FailureCount: 3}}, entry.Sinks)
+			assert.False(t, entry.Success)
+			assert.Equal(t, "failed to setup extractor", entry.Error)
+			assert.False(t, entry.FinishedAt.Before(entry.StartedAt))
+		}
+	})
+
+	t.Run("should add runs whose sinks failed to send records as failed", func(t *testing.T) {
+		store := newStore(t)
+		rcp := recipe.Recipe{Name: "recipe-a", Source: recipe.PluginRecipe{Name: "mysql"}}
+
+		recorder := history.NewRecorder(store, utils.Logger)
+		recorder.OnRunStart(rcp)
+		recorder.OnRunEnd(agent.Run{
+			Recipe:      rcp,
+			RecordCount: 3,
+			Sinks:       []agent.SinkRun{{Name: "console", SuccessCount: 1, FailureCount: 2}},
+			Success:     true,
+		})
+
+		entries, err := store.List("", 0)
+		assert.NoError(t, err)
+		if assert.Len(t, entries, 1) {
+			assert.False(t, entries[0].Success)
+			assert.Empty(t, entries[0].Error)
+		}
+	})
+}
+
+func TestTrends(t *testing.T) {
+	t.Run("should report recipe whose latest runs failed", func(t *testing.T) {
+		trends := history.Trends([]history.Entry{
+			{ID: 3, Recipe: "recipe-a"},
+			{ID: 2, Recipe: "recipe-a"},
+			{ID: 1, Recipe: "recipe-a", Success: true},
+		})
+		assert.Equal(t, []history.Trend{
+			{Recipe: "recipe-a", Kind: history.TrendFailing, RunID: 3, Message: "last 2 run(s) failed"},
+		}, trends)
+	})
+
+	t.Run("should report records dropping to zero", func(t *testing.T) {
+		trends := history.Trends([]history.Entry{
+			{ID: 3, Recipe: "recipe-a", Success: true, RecordCount: 0, DurationInMs: 100},
+			{ID: 2, Recipe: "recipe-a", Success: true, RecordCount: 10, DurationInMs: 100},
+			{ID: 1, Recipe: "recipe-a", Success: true, RecordCount: 20, DurationInMs: 100},
+		})
+		assert.Equal(t, []history.Trend{
+			{Recipe: "recipe-a", Kind: history.TrendNoRecords, RunID: 3, Message: "no records extracted, 15 on average before"},
+		}, trends)
+	})
+
+	t.Run("should report fewer records and doubled duration", func(t *testing.T) {
+		trends := history.Trends([]history.Entry{
+			{ID: 3, Recipe: "recipe-a", Success: true, RecordCount: 4, DurationInMs: 400},
+			{ID: 2, Recipe: "recipe-a", Success: true, RecordCount: 10, DurationInMs: 200},
+			{ID: 1, Recipe: "recipe-a"},
+		})
+		assert.Equal(t, []history.Trend{
+			{Recipe: "recipe-a", Kind: history.TrendFewerRecords, RunID: 3, Message: "4 records extracted, 10 on average before"},
+			{Recipe: "recipe-a", Kind: history.TrendSlower, RunID: 3, Message: "took 400 ms, 200 ms on average before"},
+		}, trends)
+	})
+
+	t.Run("should not report steady recipes", func(t *testing.T) {
+		trends := history.Trends([]history.Entry{
+			{ID: 4, Recipe: "recipe-b", Success: true, RecordCount: 10, DurationInMs: 120},
+			{ID: 3, Recipe: "recipe-a", Success: true, RecordCount: 5, DurationInMs: 100},
+			{ID: 2, Recipe: "recipe-b", Success: true, RecordCount: 11, DurationInMs: 100},
+			{ID: 1, Recipe: "recipe-a"},
+		})
+		assert.Empty(t, trends)
+	})
+}
+
+func newStore(t *testing.T) *history.Store {
+	store, err := history.NewStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		store.Close()
+	})
+	return store
+}
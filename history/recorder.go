@@ -0,0 +1,68 @@
+package history
+
+import (
+	"sync"
+	"time"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/recipe"
+	"github.com/odpf/salt/log"
+)
+
+// Recorder observes the runs of the agent and adds them to the store once they end.
+type Recorder struct {
+	agent.BaseObserver
+	store  *Store
+	logger log.Logger
+
+	mu      sync.Mutex
+	started map[string]time.Time
+}
+
+// NewRecorder returns a Recorder adding runs to the store, failures to add them are logged.
+func NewRecorder(store *Store, logger log.Logger) *Recorder {
+	return &Recorder{
+		store:   store,
+		logger:  logger,
+		started: make(map[string]time.Time),
+	}
+}
+
+func (r *Recorder) OnRunStart(rcp recipe.Recipe) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.started[rcp.Name] = time.Now()
+}
+
+func (r *Recorder) OnRunEnd(run agent.Run) {
+	finishedAt := time.Now()
+
+	r.mu.Lock()
+	startedAt, ok := r.started[run.Recipe.Name]
+	delete(r.started, run.Recipe.Name)
+	r.mu.Unlock()
+	if !ok {
+		startedAt = finishedAt.Add(-time.Duration(run.DurationInMs) * time.Millisecond)
+	}
+
+	entry := Entry{
+		Recipe:       run.Recipe.Name,
+		Source:       run.Recipe.SourceNames(),
+		StartedAt:    startedAt,
+		FinishedAt:   finishedAt,
+		DurationInMs: run.DurationInMs,
+		RecordCount:  run.RecordCount,
+		DroppedCount: run.DroppedCount,
+		SkippedCount: run.SkippedCount,
+		Sinks:        run.Sinks,
+		Success:      run.Success && run.SinkFailureCount() == 0,
+	}
+	if run.Error != nil {
+		entry.Error = run.Error.Error()
+	}
+
+	if err := r.store.Add(entry); err != nil {
+		r.logger.Error("failed to record run history", "recipe", run.Recipe.Name, "err", err.Error())
+	}
+}
@@ -0,0 +1,140 @@
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/state"
+	"github.com/pkg/errors"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	recipe         TEXT NOT NULL,
+	source         TEXT NOT NULL,
+	started_at     TEXT NOT NULL,
+	finished_at    TEXT NOT NULL,
+	duration_ms    INTEGER NOT NULL,
+	record_count   INTEGER NOT NULL,
+	dropped_count  INTEGER NOT NULL,
+	skipped_count  INTEGER NOT NULL,
+	sinks          TEXT NOT NULL,
+	success        BOOLEAN NOT NULL,
+	error          TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS runs_recipe ON runs (recipe, id)`
+
+// Entry is a finished run of a recipe.
+type Entry struct {
+	ID           int64           `json:"id"`
+	Recipe       string          `json:"recipe"`
+	Source       string          `json:"source"`
+	StartedAt    time.Time       `json:"started_at"`
+	FinishedAt   time.Time       `json:"finished_at"`
+	DurationInMs int             `json:"duration_in_ms"`
+	RecordCount  int             `json:"record_count"`
+	DroppedCount int             `json:"dropped_count"`
+	SkippedCount int             `json:"skipped_count"`
+	Sinks        []agent.SinkRun `json:"sinks"`
+	Success      bool            `json:"success"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// Store keeps the run history in a SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore opens the SQLite database on path and creates the runs table if needed.
+func NewStore(path string) (*Store, error) {
+	db, err := state.OpenSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = db.Exec(schema); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to create runs table")
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Add saves the entry, its ID is set by the store.
+func (s *Store) Add(entry Entry) error {
+	sinks, err := json.Marshal(entry.Sinks)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal sinks")
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO runs (recipe, source, started_at, finished_at, duration_ms, record_count, dropped_count, skipped_count, sinks, success, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Recipe, entry.Source,
+		entry.StartedAt.UTC().Format(time.RFC3339Nano), entry.FinishedAt.UTC().Format(time.RFC3339Nano),
+		entry.DurationInMs, entry.RecordCount, entry.DroppedCount, entry.SkippedCount,
+		string(sinks), entry.Success, entry.Error,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to save run")
+	}
+
+	return nil
+}
+
+// List returns up to limit entries of the recipe, or of all recipes when it is empty, the latest first.
+// A limit of 0 returns all the entries.
+func (s *Store) List(recipe string, limit int) ([]Entry, error) {
+	query := `SELECT id, recipe, source, started_at, finished_at, duration_ms, record_count, dropped_count, skipped_count, sinks, success, error
+		FROM runs WHERE (? = '' OR recipe = ?) ORDER BY id DESC`
+	args := []interface{}{recipe, recipe}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query runs")
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var (
+			entry                 Entry
+			startedAt, finishedAt string
+			sinks                 string
+		)
+		err := rows.Scan(
+			&entry.ID, &entry.Recipe, &entry.Source, &startedAt, &finishedAt,
+			&entry.DurationInMs, &entry.RecordCount, &entry.DroppedCount, &entry.SkippedCount,
+			&sinks, &entry.Success, &entry.Error,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read run")
+		}
+		if entry.StartedAt, err = time.Parse(time.RFC3339Nano, startedAt); err != nil {
+			return nil, errors.Wrapf(err, "invalid start time of run %d", entry.ID)
+		}
+		if entry.FinishedAt, err = time.Parse(time.RFC3339Nano, finishedAt); err != nil {
+			return nil, errors.Wrapf(err, "invalid finish time of run %d", entry.ID)
+		}
+		if err := json.Unmarshal([]byte(sinks), &entry.Sinks); err != nil {
+			return nil, errors.Wrapf(err, "invalid sinks of run %d", entry.ID)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read runs")
+	}
+
+	return entries, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
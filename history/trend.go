@@ -0,0 +1,101 @@
+package history
+
+import (
+	"fmt"
+)
+
+// TrendWindow is the number of previous successful runs the latest run of a recipe is compared to.
+const TrendWindow = 5
+
+// Trend kinds
+const (
+	TrendFailing      = "failing"
+	TrendNoRecords    = "no_records"
+	TrendFewerRecords = "fewer_records"
+	TrendSlower       = "slower"
+)
+
+// Trend is a change of the latest run of a recipe worth looking into.
+type Trend struct {
+	Recipe  string `json:"recipe"`
+	Kind    string `json:"kind"`
+	RunID   int64  `json:"run_id"`
+	Message string `json:"message"`
+}
+
+// Trends compares the latest run of each recipe to its previous successful runs, up to TrendWindow of them.
+// Entries have to be the latest first, as returned by Store.List.
+//
+// It reports recipes whose latest runs failed, and successful latest runs that
+// extracted no records or less than half the usual records, or took at least twice the usual duration.
+func Trends(entries []Entry) []Trend {
+	var (
+		order   []string
+		byName  = make(map[string][]Entry)
+		results []Trend
+	)
+	for _, entry := range entries {
+		if _, ok := byName[entry.Recipe]; !ok {
+			order = append(order, entry.Recipe)
+		}
+		byName[entry.Recipe] = append(byName[entry.Recipe], entry)
+	}
+
+	for _, name := range order {
+		results = append(results, recipeTrends(byName[name])...)
+	}
+
+	return results
+}
+
+func recipeTrends(entries []Entry) []Trend {
+	latest := entries[0]
+	trend := func(kind, format string, args ...interface{}) Trend {
+		return Trend{Recipe: latest.Recipe, Kind: kind, RunID: latest.ID, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if !latest.Success {
+		failures := 0
+		for _, entry := range entries {
+			if entry.Success {
+				break
+			}
+			failures++
+		}
+		return []Trend{trend(TrendFailing, "last %d run(s) failed", failures)}
+	}
+
+	var baseline []Entry
+	for _, entry := range entries[1:] {
+		if len(baseline) == TrendWindow {
+			break
+		}
+		if entry.Success {
+			baseline = append(baseline, entry)
+		}
+	}
+	if len(baseline) == 0 {
+		return nil
+	}
+
+	var records, duration float64
+	for _, entry := range baseline {
+		records += float64(entry.RecordCount)
+		duration += float64(entry.DurationInMs)
+	}
+	records /= float64(len(baseline))
+	duration /= float64(len(baseline))
+
+	var trends []Trend
+	switch {
+	case latest.RecordCount == 0 && records > 0:
+		trends = append(trends, trend(TrendNoRecords, "no records extracted, %.0f on average before", records))
+	case float64(latest.RecordCount) < records/2:
+		trends = append(trends, trend(TrendFewerRecords, "%d records extracted, %.0f on average before", latest.RecordCount, records))
+	}
+	if duration > 0 && float64(latest.DurationInMs) >= 2*duration {
+		trends = append(trends, trend(TrendSlower, "took %d ms, %.0f ms on average before", latest.DurationInMs, duration))
+	}
+
+	return trends
+}
@@ -21,17 +21,21 @@ const (
 func main() {
 	// Execute the root command
 	root := cmd.New()
-	cmd, err := root.ExecuteC()
+	command, err := root.ExecuteC()
 
 	if err == nil {
 		return
 	}
 
+	if cmd.IsSilentError(err) {
+		os.Exit(exitError)
+	}
+
 	if cmdx.IsCmdErr(err) {
 		if !strings.HasSuffix(err.Error(), "\n") {
 			fmt.Println()
 		}
-		fmt.Println(cmd.UsageString())
+		fmt.Println(command.UsageString())
 		os.Exit(exitOK)
 	}
 
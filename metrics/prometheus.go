@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// PrometheusMonitor represents the prometheus monitor, it observes runs of the agent.
+// Metrics are either scraped from Handler or pushed to a Pushgateway with Push.
+type PrometheusMonitor struct {
+	agent.BaseObserver
+	registry     *prometheus.Registry
+	runDuration  *prometheus.HistogramVec
+	runs         *prometheus.CounterVec
+	runRecords   *prometheus.CounterVec
+	runTimeouts  *prometheus.CounterVec
+	sinkBatches  *prometheus.CounterVec
+	sinkRecords  *prometheus.CounterVec
+	sinkLatency  *prometheus.HistogramVec
+	sinkAttempts *prometheus.CounterVec
+	sinkBlocked  *prometheus.CounterVec
+	sinkSpilled  *prometheus.CounterVec
+}
+
+// NewPrometheusMonitor creates a new PrometheusMonitor with its metrics in the given namespace
+func NewPrometheusMonitor(namespace string) *PrometheusMonitor {
+	runLabels := []string{"recipe", "extractor", "success"}
+	sinkLabels := []string{"recipe", "sink", "success"}
+
+	m := &PrometheusMonitor{
+		registry: prometheus.NewRegistry(),
+		runDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "run_duration_seconds",
+			Help:      "Duration of recipe runs.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}, runLabels),
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "runs_total",
+			Help:      "Number of recipe runs.",
+		}, runLabels),
+		runRecords: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "run_records_total",
+			Help:      "Number of records extracted by recipe runs.",
+		}, runLabels),
+		runTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "run_timeouts_total",
+			Help:      "Number of recipe runs ending with a timeout.",
+		}, []string{"recipe", "extractor", "stage", "plugin"}),
+		sinkBatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sink_batches_total",
+			Help:      "Number of batches sent to sinks.",
+		}, sinkLabels),
+		sinkRecords: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sink_records_total",
+			Help:      "Number of records sent to sinks.",
+		}, sinkLabels),
+		sinkLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "sink_batch_duration_seconds",
+			Help:      "Time taken by sinks to send a batch, including retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, sinkLabels),
+		sinkAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sink_attempts_total",
+			Help:      "Number of attempts made by sinks to send batches.",
+		}, sinkLabels),
+		sinkBlocked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sink_blocked_seconds_total",
+			Help:      "Time recipe runs waited for sinks to have room for more records.",
+		}, []string{"recipe", "sink"}),
+		sinkSpilled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sink_spilled_records_total",
+			Help:      "Number of records spilled to disk while sink buffers were full.",
+		}, []string{"recipe", "sink"}),
+	}
+
+	m.registry.MustRegister(
+		m.runDuration,
+		m.runs,
+		m.runRecords,
+		m.runTimeouts,
+		m.sinkBatches,
+		m.sinkRecords,
+		m.sinkLatency,
+		m.sinkAttempts,
+		m.sinkBlocked,
+		m.sinkSpilled,
+	)
+
+	return m
+}
+
+// OnRunEnd records a run behavior
+func (m *PrometheusMonitor) OnRunEnd(run agent.Run) {
+	labels := prometheus.Labels{
+		"recipe":    run.Recipe.Name,
+		"extractor": run.Recipe.SourceNames(),
+		"success":   strconv.FormatBool(run.Success),
+	}
+	m.runDuration.With(labels).Observe(float64(run.DurationInMs) / 1000)
+	m.runs.With(labels).Inc()
+	m.runRecords.With(labels).Add(float64(run.RecordCount))
+
+	// sink timeouts are counted in the sinks of the run, whether the run stopped on them or not
+	var timeoutErr agent.TimeoutError
+	if errors.As(run.Error, &timeoutErr) && timeoutErr.Stage != agent.TimeoutStageSink {
+		m.runTimeouts.With(prometheus.Labels{
+			"recipe":    run.Recipe.Name,
+			"extractor": run.Recipe.SourceNames(),
+			"stage":     string(timeoutErr.Stage),
+			"plugin":    timeoutErr.PluginName,
+		}).Inc()
+	}
+
+	for _, sink := range run.Sinks {
+		if sink.TimeoutCount > 0 {
+			m.runTimeouts.With(prometheus.Labels{
+				"recipe":    run.Recipe.Name,
+				"extractor": run.Recipe.SourceNames(),
+				"stage":     string(agent.TimeoutStageSink),
+				"plugin":    sink.Name,
+			}).Add(float64(sink.TimeoutCount))
+		}
+		labels := prometheus.Labels{"recipe": run.Recipe.Name, "sink": sink.Name}
+		m.sinkBlocked.With(labels).Add(float64(sink.BlockedInMs) / 1000)
+		m.sinkSpilled.With(labels).Add(float64(sink.SpilledCount))
+	}
+}
+
+// OnSinkBatch records a individual sink behavior in a run
+func (m *PrometheusMonitor) OnSinkBatch(recipeName string, batch agent.SinkBatch) {
+	labels := prometheus.Labels{
+		"recipe":  recipeName,
+		"sink":    batch.Sink,
+		"success": strconv.FormatBool(batch.Err == nil),
+	}
+	m.sinkBatches.With(labels).Inc()
+	m.sinkRecords.With(labels).Add(float64(batch.RecordCount))
+	m.sinkLatency.With(labels).Observe(batch.Latency.Seconds())
+	m.sinkAttempts.With(labels).Add(float64(batch.Attempts))
+}
+
+// Handler returns the handler serving the metrics to be scraped
+func (m *PrometheusMonitor) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Push sends the metrics to the Pushgateway at the url, replacing the ones previously pushed for the job
+func (m *PrometheusMonitor) Push(url, job string) error {
+	if err := push.New(url, job).Gatherer(m.registry).Push(); err != nil {
+		return errors.Wrap(err, "failed to push metrics")
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package metrics_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/metrics"
+	"github.com/odpf/meteor/recipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusMonitor(t *testing.T) {
+	rcp := recipe.Recipe{
+		Name: "test-recipe",
+		Source: recipe.PluginRecipe{
+			Name: "mysql",
+		},
+	}
+
+	t.Run("should expose run metrics with labels", func(t *testing.T) {
+		monitor := metrics.NewPrometheusMonitor("meteor")
+		monitor.OnRunEnd(agent.Run{Recipe: rcp, DurationInMs: 1500, RecordCount: 4, Success: true})
+
+		body := scrape(t, monitor)
+		assert.Contains(t, body, `meteor_runs_total{extractor="mysql",recipe="test-recipe",success="true"} 1`)
+		assert.Contains(t, body, `meteor_run_records_total{extractor="mysql",recipe="test-recipe",success="true"} 4`)
+		assert.Contains(t, body, `meteor_run_duration_seconds_sum{extractor="mysql",recipe="test-recipe",success="true"} 1.5`)
+	})
+
+	t.Run("should expose time blocked by sinks", func(t *testing.T) {
+		monitor := metrics.NewPrometheusMonitor("meteor")
+		monitor.OnRunEnd(agent.Run{
+			Recipe:  rcp,
+			Success: true,
+			Sinks:   []agent.SinkRun{{Name: "compass", SuccessCount: 4, BlockedInMs: 2500, SpilledCount: 3}},
+		})
+
+		body := scrape(t, monitor)
+		assert.Contains(t, body, `meteor_sink_blocked_seconds_total{recipe="test-recipe",sink="compass"} 2.5`)
+		assert.Contains(t, body, `meteor_sink_spilled_records_total{recipe="test-recipe",sink="compass"} 3`)
+	})
+
+	t.Run("should expose sink metrics with labels", func(t *testing.T) {
+		monitor := metrics.NewPrometheusMonitor("meteor")
+		monitor.OnSinkBatch(rcp.Name, agent.SinkBatch{Sink: "kafka", RecordCount: 3, Attempts: 1, Latency: time.Second})
+		monitor.OnSinkBatch(rcp.Name, agent.SinkBatch{Sink: "kafka", RecordCount: 2, Attempts: 3, Err: errors.New("sink error")})
+
+		body := scrape(t, monitor)
+		assert.Contains(t, body, `meteor_sink_batches_total{recipe="test-recipe",sink="kafka",success="true"} 1`)
+		assert.Contains(t, body, `meteor_sink_records_total{recipe="test-recipe",sink="kafka",success="true"} 3`)
+		assert.Contains(t, body, `meteor_sink_batches_total{recipe="test-recipe",sink="kafka",success="false"} 1`)
+		assert.Contains(t, body, `meteor_sink_attempts_total{recipe="test-recipe",sink="kafka",success="false"} 3`)
+	})
+
+	t.Run("should expose timeout metric when run timed out", func(t *testing.T) {
+		monitor := metrics.NewPrometheusMonitor("meteor")
+		monitor.OnRunEnd(agent.Run{
+			Recipe: rcp,
+			Error: agent.TimeoutError{
+				Stage:      agent.TimeoutStageExtract,
+				PluginName: "mysql",
+				Timeout:    time.Second,
+			},
+		})
+
+		body := scrape(t, monitor)
+		assert.Contains(t, body, `meteor_run_timeouts_total{extractor="mysql",plugin="mysql",recipe="test-recipe",stage="extract"} 1`)
+	})
+
+	t.Run("should expose timeout metric of sink calls that timed out", func(t *testing.T) {
+		monitor := metrics.NewPrometheusMonitor("meteor")
+		monitor.OnRunEnd(agent.Run{
+			Recipe: rcp,
+			Error: agent.TimeoutError{
+				Stage:      agent.TimeoutStageSink,
+				PluginName: "compass",
+				Timeout:    time.Second,
+			},
+			Sinks: []agent.SinkRun{{Name: "compass", FailureCount: 2, TimeoutCount: 2}},
+		})
+
+		body := scrape(t, monitor)
+		assert.Contains(t, body, `meteor_run_timeouts_total{extractor="mysql",plugin="compass",recipe="test-recipe",stage="sink"} 2`)
+	})
+
+	t.Run("should push metrics to the pushgateway", func(t *testing.T) {
+		var (
+			method string
+			path   string
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method, path = r.Method, r.URL.Path
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		monitor := metrics.NewPrometheusMonitor("meteor")
+		monitor.OnRunEnd(agent.Run{Recipe: rcp, Success: true})
+
+		err := monitor.Push(server.URL, "meteor")
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodPut, method)
+		assert.Equal(t, "/metrics/job/meteor", path)
+	})
+
+	t.Run("should return error when pushgateway rejects metrics", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		monitor := metrics.NewPrometheusMonitor("meteor")
+		err := monitor.Push(server.URL, "meteor")
+		assert.Error(t, err)
+	})
+}
+
+func scrape(t *testing.T, monitor *metrics.PrometheusMonitor) string {
+	rec := httptest.NewRecorder()
+	monitor.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, err := ioutil.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -17,10 +18,12 @@ const (
 	runRecordCountMetricName = "runRecordCount"
 	runMetricName            = "run"
 	pluginRunMetricName      = "runPlugin"
+	runTimeoutMetricName     = "runTimeout"
 )
 
-// StatsdMonitor represents the statsd monitor.
+// StatsdMonitor represents the statsd monitor, it observes runs of the agent.
 type StatsdMonitor struct {
+	agent.BaseObserver
 	client statsdClient
 	prefix string
 }
@@ -33,8 +36,8 @@ func NewStatsdMonitor(client statsdClient, prefix string) *StatsdMonitor {
 	}
 }
 
-// RecordRun records a run behavior
-func (m *StatsdMonitor) RecordRun(run agent.Run) {
+// OnRunEnd records a run behavior
+func (m *StatsdMonitor) OnRunEnd(run agent.Run) {
 	m.client.Timing(
 		m.createMetricName(runDurationMetricName, run.Recipe, run.Success),
 		int64(run.DurationInMs),
@@ -46,19 +49,43 @@ func (m *StatsdMonitor) RecordRun(run agent.Run) {
 		m.createMetricName(runRecordCountMetricName, run.Recipe, run.Success),
 		run.RecordCount,
 	)
+
+	// sink timeouts are counted in the sinks of the run, whether the run stopped on them or not
+	var timeoutErr agent.TimeoutError
+	if errors.As(run.Error, &timeoutErr) && timeoutErr.Stage != agent.TimeoutStageSink {
+		m.client.Increment(m.createTimeoutMetricName(run.Recipe, timeoutErr.Stage, timeoutErr.PluginName))
+	}
+	for _, sink := range run.Sinks {
+		if sink.TimeoutCount > 0 {
+			m.client.IncrementByValue(m.createTimeoutMetricName(run.Recipe, agent.TimeoutStageSink, sink.Name), sink.TimeoutCount)
+		}
+	}
+}
+
+// createTimeoutMetricName creates a timeout metric name for a given recipe, stage and plugin
+func (m *StatsdMonitor) createTimeoutMetricName(rcp recipe.Recipe, stage agent.TimeoutStage, pluginName string) string {
+	return fmt.Sprintf(
+		"%s.%s,name=%s,extractor=%s,stage=%s,plugin=%s",
+		m.prefix,
+		runTimeoutMetricName,
+		rcp.Name,
+		extractorTag(rcp),
+		stage,
+		pluginName,
+	)
 }
 
-// RecordPlugin records a individual plugin behavior in a run
-func (m *StatsdMonitor) RecordPlugin(recipeName, pluginName, pluginType string, success bool) {
+// OnSinkBatch records a individual sink behavior in a run
+func (m *StatsdMonitor) OnSinkBatch(recipeName string, batch agent.SinkBatch) {
 	m.client.Increment(
 		fmt.Sprintf(
 			"%s.%s,recipe_name=%s,name=%s,type=%s,success=%t",
 			m.prefix,
 			pluginRunMetricName,
 			recipeName,
-			pluginName,
-			pluginType,
-			success,
+			batch.Sink,
+			"sink",
+			batch.Err == nil,
 		),
 	)
 }
@@ -76,10 +103,15 @@ func (m *StatsdMonitor) createMetricName(metricName string, recipe recipe.Recipe
 		metricName,
 		recipe.Name,
 		successText,
-		recipe.Source.Name,
+		extractorTag(recipe),
 	)
 }
 
+// extractorTag returns the extractor names of the recipe as a tag value, tags being separated by commas.
+func extractorTag(rcp recipe.Recipe) string {
+	return strings.ReplaceAll(rcp.SourceNames(), ",", "+")
+}
+
 type statsdClient interface {
 	Timing(string, int64)
 	Increment(string)
@@ -1,10 +1,12 @@
 package metrics_test
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/odpf/meteor/test/utils"
 
@@ -74,7 +76,7 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
-func TestStatsdMonitorRecordRun(t *testing.T) {
+func TestStatsdMonitorOnRunEnd(t *testing.T) {
 	statsdPrefix := "testprefix"
 
 	t.Run("should create metrics with the correct name and value", func(t *testing.T) {
@@ -115,7 +117,7 @@ func TestStatsdMonitorRecordRun(t *testing.T) {
 		defer client.AssertExpectations(t)
 
 		monitor := metrics.NewStatsdMonitor(client, statsdPrefix)
-		monitor.RecordRun(agent.Run{Recipe: recipe, DurationInMs: duration, RecordCount: recordCount, Success: false})
+		monitor.OnRunEnd(agent.Run{Recipe: recipe, DurationInMs: duration, RecordCount: recordCount, Success: false})
 	})
 
 	t.Run("should set success field to true on success", func(t *testing.T) {
@@ -156,11 +158,95 @@ func TestStatsdMonitorRecordRun(t *testing.T) {
 		defer client.AssertExpectations(t)
 
 		monitor := metrics.NewStatsdMonitor(client, statsdPrefix)
-		monitor.RecordRun(agent.Run{Recipe: recipe, DurationInMs: duration, RecordCount: recordCount, Success: true})
+		monitor.OnRunEnd(agent.Run{Recipe: recipe, DurationInMs: duration, RecordCount: recordCount, Success: true})
 	})
 }
 
-func TestStatsdMonitorRecordPlugin(t *testing.T) {
+func TestStatsdMonitorOnRunEndTimeout(t *testing.T) {
+	statsdPrefix := "testprefix"
+
+	t.Run("should record timeout metric when run timed out", func(t *testing.T) {
+		recipe := recipe.Recipe{
+			Name: "test-recipe",
+			Source: recipe.PluginRecipe{
+				Name: "tableau",
+			},
+		}
+		duration := 100
+		timeoutMetric := fmt.Sprintf(
+			"%s.runTimeout,name=%s,extractor=%s,stage=%s,plugin=%s",
+			statsdPrefix,
+			recipe.Name,
+			recipe.Source.Name,
+			"extract",
+			recipe.Source.Name,
+		)
+
+		client := new(mockStatsdClient)
+		client.On("Timing", mock.AnythingOfType("string"), int64(duration))
+		client.On("Increment", mock.AnythingOfType("string")).Once()
+		client.On("Increment", timeoutMetric).Once()
+		client.On("IncrementByValue", mock.AnythingOfType("string"), 0)
+		defer client.AssertExpectations(t)
+
+		monitor := metrics.NewStatsdMonitor(client, statsdPrefix)
+		monitor.OnRunEnd(agent.Run{
+			Recipe:       recipe,
+			DurationInMs: duration,
+			Error: fmt.Errorf("failed to run extractor: %w", agent.TimeoutError{
+				Stage:      agent.TimeoutStageExtract,
+				PluginName: recipe.Source.Name,
+				Timeout:    time.Minute,
+			}),
+		})
+	})
+}
+
+func TestStatsdMonitorOnRunEndSinkTimeout(t *testing.T) {
+	statsdPrefix := "testprefix"
+
+	t.Run("should record timeout metric of sink calls that timed out", func(t *testing.T) {
+		recipe := recipe.Recipe{
+			Name: "test-recipe",
+			Source: recipe.PluginRecipe{
+				Name: "tableau",
+			},
+			Sinks: []recipe.PluginRecipe{
+				{Name: "compass"},
+			},
+		}
+		duration := 100
+		timeoutMetric := fmt.Sprintf(
+			"%s.runTimeout,name=%s,extractor=%s,stage=%s,plugin=%s",
+			statsdPrefix,
+			recipe.Name,
+			recipe.Source.Name,
+			"sink",
+			"compass",
+		)
+
+		client := new(mockStatsdClient)
+		client.On("Timing", mock.AnythingOfType("string"), int64(duration))
+		client.On("Increment", mock.AnythingOfType("string")).Once()
+		client.On("IncrementByValue", mock.AnythingOfType("string"), 0)
+		client.On("IncrementByValue", timeoutMetric, 2).Once()
+		defer client.AssertExpectations(t)
+
+		monitor := metrics.NewStatsdMonitor(client, statsdPrefix)
+		monitor.OnRunEnd(agent.Run{
+			Recipe:       recipe,
+			DurationInMs: duration,
+			Error: fmt.Errorf("failed to broadcast stream: %w", agent.TimeoutError{
+				Stage:      agent.TimeoutStageSink,
+				PluginName: "compass",
+				Timeout:    time.Minute,
+			}),
+			Sinks: []agent.SinkRun{{Name: "compass", FailureCount: 2, TimeoutCount: 2}},
+		})
+	})
+}
+
+func TestStatsdMonitorOnSinkBatch(t *testing.T) {
 	statsdPrefix := "testprefix"
 
 	t.Run("should create metrics with the correct name and value", func(t *testing.T) {
@@ -188,7 +274,7 @@ func TestStatsdMonitorRecordPlugin(t *testing.T) {
 		defer client.AssertExpectations(t)
 
 		monitor := metrics.NewStatsdMonitor(client, statsdPrefix)
-		monitor.RecordPlugin(recipe.Name, recipe.Sinks[0].Name, "sink", false)
+		monitor.OnSinkBatch(recipe.Name, agent.SinkBatch{Sink: recipe.Sinks[0].Name, RecordCount: 1, Attempts: 1, Err: errors.New("sink error")})
 	})
 
 	t.Run("should set success field to true on success", func(t *testing.T) {
@@ -216,7 +302,7 @@ func TestStatsdMonitorRecordPlugin(t *testing.T) {
 		defer client.AssertExpectations(t)
 
 		monitor := metrics.NewStatsdMonitor(client, statsdPrefix)
-		monitor.RecordPlugin(recipe.Name, recipe.Sinks[0].Name, "sink", true)
+		monitor.OnSinkBatch(recipe.Name, agent.SinkBatch{Sink: recipe.Sinks[0].Name, RecordCount: 1, Attempts: 1})
 	})
 }
 
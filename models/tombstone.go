@@ -0,0 +1,24 @@
+package models
+
+import (
+	commonv1beta1 "github.com/odpf/meteor/models/odpf/assets/common/v1beta1"
+)
+
+// EventActionDelete is the action of the event of a tombstone record, telling sinks its asset was deleted.
+const EventActionDelete = "delete"
+
+// EventMetadata is metadata carrying an event, such as the event of a tombstone record.
+type EventMetadata interface {
+	GetEvent() *commonv1beta1.Event
+}
+
+// IsTombstone returns true when the record tells its asset was deleted from the source.
+// Tombstone records only carry the resource of the asset and the delete event.
+func IsTombstone(record Record) bool {
+	em, ok := record.Data().(EventMetadata)
+	if !ok {
+		return false
+	}
+
+	return em.GetEvent().GetAction() == EventActionDelete
+}
@@ -1,6 +1,9 @@
 package plugins
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+)
 
 // ConfigError contains fields to check error
 type ConfigError struct {
@@ -57,3 +60,42 @@ func NewRetryError(err error) error {
 	}
 	return RetryError{Err: err}
 }
+
+// PermanentError is an error signalling that the operation fails the same way however many times it is retried,
+// such as a rejected payload or invalid credentials. It is never retried, even when it wraps a RetryError.
+type PermanentError struct {
+	Err error
+}
+
+func (e PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e PermanentError) Unwrap() error {
+	return e.Err
+}
+
+func (e PermanentError) Is(target error) bool {
+	_, ok := target.(PermanentError)
+	return ok
+}
+
+func NewPermanentError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return PermanentError{Err: err}
+}
+
+// NewStatusError classifies the error of a request by the HTTP status code of its response.
+// Too many requests, request timeout and server errors are RetryError, other statuses are PermanentError.
+// Requests that did not get a response, such as on connection errors, can succeed once sent again,
+// so sinks return their error as a RetryError with NewRetryError.
+func NewStatusError(statusCode int, err error) error {
+	switch {
+	case statusCode == http.StatusTooManyRequests, statusCode == http.StatusRequestTimeout, statusCode >= 500:
+		return NewRetryError(err)
+	default:
+		return NewPermanentError(err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"html/template"
 	"strings"
 	"sync"
+	"time"
 
 	"cloud.google.com/go/bigquery"
 	"github.com/odpf/meteor/models"
@@ -19,6 +20,7 @@ import (
 	"github.com/odpf/meteor/utils"
 	"github.com/odpf/salt/log"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -59,12 +61,16 @@ service_account_json: |-
 collect_table_usage: false
 usage_period_in_day: 7`
 
+// stateKeyLastModified is the state key of the latest table modification time seen by the extractor
+const stateKeyLastModified = "last_modified"
+
 // Extractor manages the communication with the bigquery service
 type Extractor struct {
 	logger    log.Logger
 	client    *bigquery.Client
 	config    Config
 	galClient *auditlog.AuditLog
+	state     plugins.State
 }
 
 func New(logger log.Logger) *Extractor {
@@ -80,6 +86,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Big Query table metadata and metrics",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"gcp", "table", "extractor"},
 	}
@@ -90,6 +97,11 @@ func (e *Extractor) Validate(configMap map[string]interface{}) (err error) {
 	return utils.BuildConfig(configMap, &Config{})
 }
 
+// SetState sets the state used to only extract tables modified since the previous run
+func (e *Extractor) SetState(state plugins.State) {
+	e.state = state
+}
+
 // Init initializes the extractor
 func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{}) (err error) {
 	err = utils.BuildConfig(configMap, &e.config)
@@ -122,6 +134,12 @@ func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{})
 
 // Extract checks if the table is valid and extracts the table schema
 func (e *Extractor) Extract(ctx context.Context, emit plugins.Emit) (err error) {
+	modifiedAfter, err := utils.GetStateTime(e.state, stateKeyLastModified)
+	if err != nil {
+		return errors.Wrap(err, "failed to read state")
+	}
+	lastModified := modifiedAfter
+	var failed bool
 
 	// Fetch and iterate over datasets
 	it := e.client.Datasets(ctx)
@@ -133,7 +151,22 @@ func (e *Extractor) Extract(ctx context.Context, emit plugins.Emit) (err error)
 		if err != nil {
 			return errors.Wrap(err, "failed to fetch dataset")
 		}
-		e.extractTable(ctx, ds, emit)
+		dsCtx, span := plugins.StartSpan(ctx, "bigquery.dataset", attribute.String("bigquery.dataset", ds.DatasetID))
+		latest, ok := e.extractTable(dsCtx, ds, modifiedAfter, emit)
+		if latest.After(lastModified) {
+			lastModified = latest
+		}
+		failed = failed || !ok
+		span.End()
+	}
+
+	// tables that failed to be extracted are only extracted again when the watermark does not move past them
+	if failed {
+		e.logger.Warn("some tables failed to be extracted, keeping the last modification time of the previous run")
+		lastModified = modifiedAfter
+	}
+	if err = utils.SetStateTime(e.state, stateKeyLastModified, lastModified); err != nil {
+		return errors.Wrap(err, "failed to save state")
 	}
 
 	return
@@ -149,8 +182,10 @@ func (e *Extractor) createClient(ctx context.Context) (*bigquery.Client, error)
 	return bigquery.NewClient(ctx, e.config.ProjectID, option.WithCredentialsJSON([]byte(e.config.ServiceAccountJSON)))
 }
 
-// Create big query client
-func (e *Extractor) extractTable(ctx context.Context, ds *bigquery.Dataset, emit plugins.Emit) {
+// extractTable emits tables of a dataset modified after modifiedAfter
+// and returns the latest modification time of the emitted tables, ok is false when a table failed to be fetched
+func (e *Extractor) extractTable(ctx context.Context, ds *bigquery.Dataset, modifiedAfter time.Time, emit plugins.Emit) (lastModified time.Time, ok bool) {
+	ok = true
 	tb := ds.Tables(ctx)
 	for {
 		table, err := tb.Next()
@@ -159,17 +194,30 @@ func (e *Extractor) extractTable(ctx context.Context, ds *bigquery.Dataset, emit
 		}
 		if err != nil {
 			e.logger.Error("failed to get table, skipping table", "err", err)
+			ok = false
 			continue
 		}
 		e.logger.Debug("extracting table", "table", table.FullyQualifiedName())
 		tmd, err := table.Metadata(ctx)
 		if err != nil {
 			e.logger.Error("failed to fetch table metadata", "err", err, "table", table.FullyQualifiedName())
+			ok = false
+			continue
+		}
+		if !modifiedAfter.IsZero() && !tmd.LastModifiedTime.After(modifiedAfter) {
+			e.logger.Debug("skipping unmodified table", "table", table.FullyQualifiedName())
 			continue
 		}
 
-		emit(models.NewRecord(e.buildTable(ctx, table, tmd)))
+		tableCtx, span := plugins.StartSpan(ctx, "bigquery.table", attribute.String("bigquery.table", table.FullyQualifiedName()))
+		emit(models.NewRecord(e.buildTable(tableCtx, table, tmd)))
+		span.End()
+		if tmd.LastModifiedTime.After(lastModified) {
+			lastModified = tmd.LastModifiedTime
+		}
 	}
+
+	return
 }
 
 // Build the bigquery table metadata
@@ -0,0 +1,72 @@
+//go:build plugins
+// +build plugins
+
+package bigquery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/alecthomas/assert"
+	"github.com/odpf/meteor/test/mocks"
+	"github.com/odpf/meteor/test/utils"
+	"google.golang.org/api/option"
+)
+
+// bigqueryAPI serves a dataset with a view modified on 2021-06-01 and a table failing to be fetched.
+var bigqueryAPI = map[string]string{
+	"/projects/sample-project/datasets": `{"datasets": [
+		{"datasetReference": {"projectId": "sample-project", "datasetId": "sample"}}
+	]}`,
+	"/projects/sample-project/datasets/sample/tables": `{"tables": [
+		{"tableReference": {"projectId": "sample-project", "datasetId": "sample", "tableId": "failing"}},
+		{"tableReference": {"projectId": "sample-project", "datasetId": "sample", "tableId": "orders"}}
+	]}`,
+	"/projects/sample-project/datasets/sample/tables/orders": `{
+		"tableReference": {"projectId": "sample-project", "datasetId": "sample", "tableId": "orders"},
+		"type": "VIEW",
+		"view": {"query": "SELECT 1"},
+		"lastModifiedTime": "1622505600000"
+	}`,
+}
+
+func TestExtractState(t *testing.T) {
+	t.Run("should keep the previous modification time when a table fails to be fetched", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			body, ok := bigqueryAPI[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				body = `{"error": {"code": 404, "message": "not found"}}`
+			}
+			w.Write([]byte(body))
+		}))
+		defer srv.Close()
+
+		ctx := context.Background()
+		client, err := bigquery.NewClient(ctx, "sample-project",
+			option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		state := mocks.NewState()
+		if err := state.Set(stateKeyLastModified, "2000-01-01T00:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+
+		extr := New(utils.Logger)
+		extr.client = client
+		extr.SetState(state)
+		emitter := mocks.NewEmitter()
+		err = extr.Extract(ctx, emitter.Push)
+		assert.NoError(t, err)
+		assert.Len(t, emitter.Get(), 1)
+
+		value, _, err := state.Get(stateKeyLastModified)
+		assert.NoError(t, err)
+		assert.Equal(t, "2000-01-01T00:00:00Z", value)
+	})
+}
@@ -58,6 +58,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Compressed, high-performance, proprietary data storage system.",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"gcp", "extractor"},
 	}
@@ -69,6 +69,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Table metadata from cassandra server.",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"oss", "extractor"},
 	}
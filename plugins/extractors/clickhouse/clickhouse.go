@@ -24,7 +24,7 @@ var summary string
 
 // Config holds the connection URL for the extractor
 type Config struct {
-	ConnectionURL string `mapstructure:"connection_url" validate:"required"`
+	ConnectionURL string `mapstructure:"connection_url" validate:"required" sensitive:"true"`
 }
 
 var sampleConfig = `
@@ -50,6 +50,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Column-oriented DBMS for online analytical processing.",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"oss", "extractor"},
 	}
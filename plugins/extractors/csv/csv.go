@@ -54,6 +54,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Comma separated file",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"file", "extractor"},
 	}
@@ -32,9 +32,10 @@ token: github_token`
 
 // Extractor manages the extraction of data from the extractor
 type Extractor struct {
-	logger log.Logger
-	config Config
-	client *github.Client
+	logger  log.Logger
+	config  Config
+	client  *github.Client
+	limiter *plugins.RateLimiter
 }
 
 // Info returns the brief information about the extractor
@@ -42,6 +43,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "User list from Github organisation.",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"platform", "extractor"},
 	}
@@ -52,6 +54,11 @@ func (e *Extractor) Validate(configMap map[string]interface{}) (err error) {
 	return utils.BuildConfig(configMap, &Config{})
 }
 
+// SetRateLimiter sets the limiter of the requests sent to the github API
+func (e *Extractor) SetRateLimiter(limiter *plugins.RateLimiter) {
+	e.limiter = limiter
+}
+
 // Init initializes the extractor
 func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{}) (err error) {
 	err = utils.BuildConfig(configMap, &e.config)
@@ -63,7 +70,7 @@ func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{})
 		&oauth2.Token{AccessToken: e.config.Token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
-	e.client = github.NewClient(tc)
+	e.client = github.NewClient(e.limiter.Client(tc))
 
 	return
 }
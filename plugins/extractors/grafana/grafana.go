@@ -32,9 +32,10 @@ api_key: your_api_key`
 
 // Extractor manages the communication with the Grafana Server
 type Extractor struct {
-	client *Client
-	config Config
-	logger log.Logger
+	client  *Client
+	config  Config
+	logger  log.Logger
+	limiter *plugins.RateLimiter
 }
 
 // New returns a pointer to an initialized Extractor Object
@@ -49,6 +50,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Dashboard list from Grafana server.",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"oss", "extractor"},
 	}
@@ -59,6 +61,11 @@ func (e *Extractor) Validate(configMap map[string]interface{}) (err error) {
 	return utils.BuildConfig(configMap, &Config{})
 }
 
+// SetRateLimiter sets the limiter of the requests sent to the grafana server
+func (e *Extractor) SetRateLimiter(limiter *plugins.RateLimiter) {
+	e.limiter = limiter
+}
+
 // Init initializes the extractor
 func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{}) (err error) {
 	// build config
@@ -68,7 +75,7 @@ func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{})
 	}
 
 	// build client
-	e.client = NewClient(&http.Client{}, e.config)
+	e.client = NewClient(e.limiter.Client(&http.Client{}), e.config)
 
 	return
 }
@@ -33,7 +33,7 @@ var defaultDBList = []string{
 
 // Config holds the connection URL for the extractor
 type Config struct {
-	ConnectionURL string `mapstructure:"connection_url" validate:"required"`
+	ConnectionURL string `mapstructure:"connection_url" validate:"required" sensitive:"true"`
 }
 
 var sampleConfig = `connection_url: "admin:pass123@tcp(localhost:3306)/"`
@@ -59,6 +59,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Table metadata from Mariadb server.",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"oss", "extractor"},
 	}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"net/http"
 
+	"github.com/odpf/meteor/plugins"
 	"github.com/pkg/errors"
 )
 
@@ -28,6 +29,11 @@ type client struct {
 	tableCache    map[int]Table
 }
 
+// SetRateLimiter sends the requests of the client through the limiter
+func (c *client) SetRateLimiter(limiter *plugins.RateLimiter) {
+	c.httpClient = limiter.Client(c.httpClient)
+}
+
 func newClient() *client {
 	return &client{
 		httpClient:    &http.Client{},
@@ -38,12 +38,16 @@ type Config struct {
 	SessionID     string `mapstructure:"session_id"`
 }
 
+// stateKeyLastModified is the state key of the latest dashboard update time seen by the extractor
+const stateKeyLastModified = "last_modified"
+
 // Extractor manages the extraction of data
 // from the metabase server
 type Extractor struct {
 	config Config
 	logger log.Logger
 	client Client
+	state  plugins.State
 }
 
 // New returns a pointer to an initialized Extractor Object
@@ -59,6 +63,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Dashboard list from Metabase server.",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"oss", "extractor"},
 	}
@@ -69,6 +74,21 @@ func (e *Extractor) Validate(configMap map[string]interface{}) (err error) {
 	return utils.BuildConfig(configMap, &Config{})
 }
 
+// SetRateLimiter sets the limiter of the requests sent to the metabase server,
+// it is only used by clients able to limit their requests
+func (e *Extractor) SetRateLimiter(limiter *plugins.RateLimiter) {
+	if c, ok := e.client.(interface {
+		SetRateLimiter(*plugins.RateLimiter)
+	}); ok {
+		c.SetRateLimiter(limiter)
+	}
+}
+
+// SetState sets the state used to only extract dashboards updated since the previous run
+func (e *Extractor) SetState(state plugins.State) {
+	e.state = state
+}
+
 func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{}) (err error) {
 	// build and validate config
 	err = utils.BuildConfig(configMap, &e.config)
@@ -86,18 +106,44 @@ func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{})
 
 // Extract collects the metadata from the source. The metadata is collected through the out channel
 func (e *Extractor) Extract(ctx context.Context, emit plugins.Emit) (err error) {
+	updatedAfter, err := utils.GetStateTime(e.state, stateKeyLastModified)
+	if err != nil {
+		return errors.Wrap(err, "failed to read state")
+	}
+	lastModified := updatedAfter
+	var failed bool
+
 	dashboards, err := e.client.GetDashboards()
 	if err != nil {
 		return errors.Wrap(err, "failed to fetch dashboard list")
 	}
 	for _, d := range dashboards {
+		updatedAt := time.Time(d.UpdatedAt)
+		if !updatedAfter.IsZero() && !updatedAt.After(updatedAfter) {
+			e.logger.Debug("skipping unchanged dashboard", "dashboard_id", d.ID)
+			continue
+		}
+
 		dashboard, err := e.buildDashboard(d)
 		if err != nil {
 			e.logger.Error("failed to build dashboard with", "dashboard_id", d.ID, "err", err.Error())
+			failed = true
 			continue
 		}
 
 		emit(models.NewRecord(dashboard))
+		if updatedAt.After(lastModified) {
+			lastModified = updatedAt
+		}
+	}
+
+	// dashboards that failed to be built are only extracted again when the watermark does not move past them
+	if failed {
+		e.logger.Warn("some dashboards failed to be extracted, keeping the last update time of the previous run")
+		lastModified = updatedAfter
+	}
+	if err = utils.SetStateTime(e.state, stateKeyLastModified, lastModified); err != nil {
+		return errors.Wrap(err, "failed to save state")
 	}
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	testutils "github.com/odpf/meteor/test/utils"
 	"github.com/pkg/errors"
@@ -97,6 +98,45 @@ func TestExtract(t *testing.T) {
 		actuals := emitter.GetAllData()
 		testutils.AssertWithJSONFile(t, "./testdata/expected.json", actuals)
 	})
+
+	t.Run("should keep the previous update time when a dashboard fails to be built", func(t *testing.T) {
+		// the failed dashboard was updated before the dashboard extracted after it
+		failed := metabase.Dashboard{ID: 2, UpdatedAt: metabase.MetabaseTime(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))}
+		dashboards := append([]metabase.Dashboard{failed}, getDashboardList(t)...)
+
+		client := new(mockClient)
+		client.On("Authenticate", host, "test-user", "test-pass", "").Return(nil)
+		client.On("GetDashboards").Return(dashboards, nil)
+		client.On("GetDashboard", 2).Return(metabase.Dashboard{}, errors.New("some error"))
+		client.On("GetDashboard", 1).Return(getDashboard(t, 1), nil)
+		client.On("GetTable", mock.Anything).Return(getTable(t, 2), nil)
+		client.On("GetDatabase", mock.Anything).Return(getDatabase(t, 2), nil)
+
+		state := mocks.NewState()
+		if err := state.Set("last_modified", "2000-01-01T00:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+		emitter := mocks.NewEmitter()
+		extr := metabase.New(client, plugins.GetLog())
+		extr.SetState(state)
+		err := extr.Init(context.TODO(), map[string]interface{}{
+			"host":           host,
+			"username":       "test-user",
+			"password":       "test-pass",
+			"instance_label": "my-metabase",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = extr.Extract(context.TODO(), emitter.Push)
+		assert.NoError(t, err)
+		assert.Len(t, emitter.Get(), 1)
+
+		value, _, err := state.Get("last_modified")
+		assert.NoError(t, err)
+		assert.Equal(t, "2000-01-01T00:00:00Z", value)
+	})
 }
 
 func getDashboardList(t *testing.T) []metabase.Dashboard {
@@ -32,7 +32,7 @@ var defaultCollections = []string{
 
 // Config holds the connection URL for the extractor
 type Config struct {
-	ConnectionURL string `mapstructure:"connection_url" validate:"required"`
+	ConnectionURL string `mapstructure:"connection_url" validate:"required" sensitive:"true"`
 }
 
 var sampleConfig = `
@@ -59,6 +59,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Collection metadata from MongoDB Server",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"oss", "extractor"},
 	}
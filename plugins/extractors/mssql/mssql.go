@@ -35,7 +35,7 @@ var defaultDBList = []string{
 
 // Config holds the connection URL for the extractor
 type Config struct {
-	ConnectionURL string `mapstructure:"connection_url" validate:"required"`
+	ConnectionURL string `mapstructure:"connection_url" validate:"required" sensitive:"true"`
 	Identifier    string `mapstructure:"identifier" validate:"required"`
 }
 
@@ -64,6 +64,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Table metdata from MSSQL server",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"microsoft", "extractor"},
 	}
@@ -7,6 +7,7 @@ import (
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/odpf/meteor/plugins"
 	pb "github.com/odpf/optimus/api/proto/odpf/optimus/core/v1beta1"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -38,7 +39,13 @@ type client struct {
 	pb.ProjectServiceClient
 	pb.JobSpecificationServiceClient
 	pb.JobRunServiceClient
-	conn *grpc.ClientConn
+	conn    *grpc.ClientConn
+	limiter *plugins.RateLimiter
+}
+
+// SetRateLimiter sets the limiter of the requests of the client, it has to be called before Connect
+func (c *client) SetRateLimiter(limiter *plugins.RateLimiter) {
+	c.limiter = limiter
 }
 
 func (c *client) Connect(ctx context.Context, host string, maxSizeInMB int) (err error) {
@@ -81,6 +88,7 @@ func (c *client) createConnection(ctx context.Context, host string, maxSizeInMB
 		),
 		grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(
 			grpc_retry.UnaryClientInterceptor(retryOpts...),
+			c.rateLimitInterceptor,
 			otelgrpc.UnaryClientInterceptor(),
 			grpc_prometheus.UnaryClientInterceptor,
 		)),
@@ -92,3 +100,15 @@ func (c *client) createConnection(ctx context.Context, host string, maxSizeInMB
 
 	return grpc.DialContext(ctx, host, opts...)
 }
+
+// rateLimitInterceptor waits for the limiter before each call, including the ones retried
+func (c *client) rateLimitInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	release, err := c.limiter.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
@@ -49,6 +49,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Optimus' jobs metadata",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"optimus", "bigquery", "job", "extractor"},
 	}
@@ -59,6 +60,16 @@ func (e *Extractor) Validate(configMap map[string]interface{}) (err error) {
 	return utils.BuildConfig(configMap, &Config{})
 }
 
+// SetRateLimiter sets the limiter of the requests sent to the optimus server,
+// it is only used by clients able to limit their requests
+func (e *Extractor) SetRateLimiter(limiter *plugins.RateLimiter) {
+	if c, ok := e.client.(interface {
+		SetRateLimiter(*plugins.RateLimiter)
+	}); ok {
+		c.SetRateLimiter(limiter)
+	}
+}
+
 // Init initializes the extractor
 func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{}) (err error) {
 	if err := utils.BuildConfig(configMap, &e.config); err != nil {
@@ -38,9 +38,10 @@ api_key: t33I8i8OFnVt3t9Bjj2RXr8nCBz0xyzVZ318Zwbj
 
 // Extractor manages the extraction of data from the redash server
 type Extractor struct {
-	config Config
-	logger log.Logger
-	client *http.Client
+	config  Config
+	logger  log.Logger
+	client  *http.Client
+	limiter *plugins.RateLimiter
 }
 
 // New returns a pointer to an initialized Extractor Object
@@ -55,6 +56,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Dashboard list from Redash server.",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"oss", "extractor"},
 	}
@@ -65,15 +67,20 @@ func (e *Extractor) Validate(configMap map[string]interface{}) (err error) {
 	return utils.BuildConfig(configMap, &Config{})
 }
 
+// SetRateLimiter sets the limiter of the requests sent to the redash server
+func (e *Extractor) SetRateLimiter(limiter *plugins.RateLimiter) {
+	e.limiter = limiter
+}
+
 // Init initializes the extractor
 func (e *Extractor) Init(_ context.Context, configMap map[string]interface{}) (err error) {
 	// build and validate config
 	if err = utils.BuildConfig(configMap, &e.config); err != nil {
 		return plugins.InvalidConfigError{}
 	}
-	e.client = &http.Client{
+	e.client = e.limiter.Client(&http.Client{
 		Timeout: 4 * time.Second,
-	}
+	})
 
 	return
 }
@@ -46,6 +46,7 @@ type Extractor struct {
 	csrfToken   string
 	logger      log.Logger
 	client      *http.Client
+	limiter     *plugins.RateLimiter
 }
 
 // New returns a pointer to an initialized Extractor Object
@@ -60,6 +61,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Dashboard list from Superset server.",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"oss", "extractor"},
 	}
@@ -70,15 +72,20 @@ func (e *Extractor) Validate(configMap map[string]interface{}) (err error) {
 	return utils.BuildConfig(configMap, &Config{})
 }
 
+// SetRateLimiter sets the limiter of the requests sent to the superset server
+func (e *Extractor) SetRateLimiter(limiter *plugins.RateLimiter) {
+	e.limiter = limiter
+}
+
 // Init initializes the extractor
 func (e *Extractor) Init(_ context.Context, configMap map[string]interface{}) (err error) {
 	// build and validate config
 	if err = utils.BuildConfig(configMap, &e.config); err != nil {
 		return plugins.InvalidConfigError{}
 	}
-	e.client = &http.Client{
+	e.client = e.limiter.Client(&http.Client{
 		Timeout: 4 * time.Second,
-	}
+	})
 	// get access token for further api calls in superset
 	if e.accessToken, err = e.getAccessToken(); err != nil {
 		return errors.Wrap(err, "failed to get access token")
@@ -42,6 +42,9 @@ type Config struct {
 	Sitename   string `mapstructure:"sitename"`
 }
 
+// stateKeyLastModified is the state key of the latest workbook update time seen by the extractor
+const stateKeyLastModified = "last_modified"
+
 // Extractor manages the extraction of data
 // from tableau server
 type Extractor struct {
@@ -49,6 +52,7 @@ type Extractor struct {
 	logger     log.Logger
 	httpClient *http.Client
 	client     Client
+	state      plugins.State
 }
 
 // Option provides extension abstraction to Extractor constructor
@@ -80,6 +84,7 @@ func (e *Extractor) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Dashboard list from Tableau server",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"oss", "extractor"},
 	}
@@ -90,6 +95,16 @@ func (e *Extractor) Validate(configMap map[string]interface{}) (err error) {
 	return utils.BuildConfig(configMap, &Config{})
 }
 
+// SetState sets the state used to only extract workbooks updated since the previous run
+func (e *Extractor) SetState(state plugins.State) {
+	e.state = state
+}
+
+// SetRateLimiter sets the limiter of the requests sent to the tableau server
+func (e *Extractor) SetRateLimiter(limiter *plugins.RateLimiter) {
+	e.client = NewClient(limiter.Client(e.httpClient))
+}
+
 func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{}) (err error) {
 	// build and validate config
 	err = utils.BuildConfig(configMap, &e.config)
@@ -107,6 +122,14 @@ func (e *Extractor) Init(ctx context.Context, configMap map[string]interface{})
 
 // Extract collects metadata from the source. The metadata is collected through the out channel
 func (e *Extractor) Extract(ctx context.Context, emit plugins.Emit) (err error) {
+	updatedAfter, err := utils.GetStateTime(e.state, stateKeyLastModified)
+	if err != nil {
+		err = errors.Wrap(err, "failed to read state")
+		return
+	}
+	lastModified := updatedAfter
+	var failed bool
+
 	projects, err := e.client.GetAllProjects(ctx)
 	if err != nil {
 		err = errors.Wrap(err, "failed to fetch list of projects")
@@ -117,17 +140,35 @@ func (e *Extractor) Extract(ctx context.Context, emit plugins.Emit) (err error)
 		workbooks, errC := e.client.GetDetailedWorkbooksByProjectName(ctx, proj.Name)
 		if errC != nil {
 			e.logger.Warn("failed to fetch list of workbook", "err", errC.Error())
+			failed = true
 			continue
 		}
 		for _, wb := range workbooks {
+			if !updatedAfter.IsZero() && !wb.UpdatedAt.After(updatedAfter) {
+				e.logger.Debug("skipping unchanged workbook", "workbook_id", wb.ID)
+				continue
+			}
 			dashboard, errB := e.buildDashboard(wb)
 			if errB != nil {
 				e.logger.Error("failed to build dashboard", "data", wb, "err", errB.Error())
+				failed = true
 				continue
 			}
 			emit(models.NewRecord(dashboard))
+			if wb.UpdatedAt.After(lastModified) {
+				lastModified = wb.UpdatedAt
+			}
 		}
 	}
+
+	// workbooks that failed to be extracted are only extracted again when the watermark does not move past them
+	if failed {
+		e.logger.Warn("some workbooks failed to be extracted, keeping the last update time of the previous run")
+		lastModified = updatedAfter
+	}
+	if err = utils.SetStateTime(e.state, stateKeyLastModified, lastModified); err != nil {
+		err = errors.Wrap(err, "failed to save state")
+	}
 	return
 }
 
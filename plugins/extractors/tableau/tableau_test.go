@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/dnaeon/go-vcr/v2/recorder"
@@ -110,6 +111,140 @@ func TestExtract(t *testing.T) {
 
 		assertJSONString(t, string(expectedJSONStringDashboardProto), actuals)
 	})
+
+	t.Run("should save latest workbook update time to state", func(t *testing.T) {
+		r, err := recorder.New("fixtures/get_workbooks_graphql_e2e")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Stop()
+
+		ctx := context.TODO()
+		state := mocks.NewState()
+		extr := tableau.New(testutils.Logger,
+			tableau.WithHTTPClient(&http.Client{
+				Transport: r,
+			}))
+		extr.SetState(state)
+		err = extr.Init(ctx, map[string]interface{}{
+			"host":       host,
+			"version":    version,
+			"identifier": "my-tableau",
+			"sitename":   sitename,
+			"username":   username,
+			"password":   password,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		emitter := mocks.NewEmitter()
+		err = extr.Extract(ctx, emitter.Push)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, emitter.Get())
+
+		_, exists, err := state.Get("last_modified")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("should keep the previous update time when workbooks of a project fail to be fetched", func(t *testing.T) {
+		r, err := recorder.New("fixtures/get_workbooks_graphql_e2e")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Stop()
+
+		ctx := context.TODO()
+		state := mocks.NewState()
+		if err := state.Set("last_modified", "2000-01-01T00:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+		extr := tableau.New(testutils.Logger,
+			tableau.WithHTTPClient(&http.Client{
+				Transport: failingTransport{RoundTripper: r, project: "test-meteor"},
+			}))
+		extr.SetState(state)
+		err = extr.Init(ctx, map[string]interface{}{
+			"host":       host,
+			"version":    version,
+			"identifier": "my-tableau",
+			"sitename":   sitename,
+			"username":   username,
+			"password":   password,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		emitter := mocks.NewEmitter()
+		err = extr.Extract(ctx, emitter.Push)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, emitter.Get())
+
+		value, _, err := state.Get("last_modified")
+		assert.NoError(t, err)
+		assert.Equal(t, "2000-01-01T00:00:00Z", value)
+	})
+
+	t.Run("should skip workbooks not updated since last run", func(t *testing.T) {
+		r, err := recorder.New("fixtures/get_workbooks_graphql_e2e")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Stop()
+
+		ctx := context.TODO()
+		state := mocks.NewState()
+		if err := state.Set("last_modified", "2999-01-01T00:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+		extr := tableau.New(testutils.Logger,
+			tableau.WithHTTPClient(&http.Client{
+				Transport: r,
+			}))
+		extr.SetState(state)
+		err = extr.Init(ctx, map[string]interface{}{
+			"host":       host,
+			"version":    version,
+			"identifier": "my-tableau",
+			"sitename":   sitename,
+			"username":   username,
+			"password":   password,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		emitter := mocks.NewEmitter()
+		err = extr.Extract(ctx, emitter.Push)
+		assert.NoError(t, err)
+		assert.Empty(t, emitter.Get())
+	})
+}
+
+// failingTransport fails the requests of the workbooks of a project, other requests are sent to the transport.
+type failingTransport struct {
+	http.RoundTripper
+	project string
+}
+
+func (t failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(string(body), t.project) {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}, nil
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return t.RoundTripper.RoundTrip(req)
 }
 
 func assertJSONString(t *testing.T, expected string, actual interface{}) {
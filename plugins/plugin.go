@@ -25,6 +25,8 @@ type Info struct {
 	SampleConfig string   `yaml:"sample_config"`
 	Tags         []string `yaml:"tags"`
 	Summary      string   `yaml:"summary"`
+	// ConfigSchema is the JSON Schema of the config of the plugin, plugins accepting any config do not have one.
+	ConfigSchema *Schema `yaml:"config_schema,omitempty"`
 }
 
 type Plugin interface {
@@ -45,6 +47,22 @@ type Extractor interface {
 	Extract(ctx context.Context, emit Emit) (err error)
 }
 
+// State is a key-value store scoped to a single recipe's source.
+// Values saved here are kept between runs of the recipe, once the run succeeded and its records reached the sinks.
+type State interface {
+	Get(key string) (value string, exists bool, err error)
+	Set(key string, value string) error
+}
+
+// StatefulExtractor is an optional interface for extractors that
+// persist watermarks between runs to extract incrementally.
+type StatefulExtractor interface {
+	Extractor
+
+	// SetState will be called before Init when the agent has a state store configured.
+	SetState(state State)
+}
+
 // Processor are the functions that are executed on the extracted data.
 type Processor interface {
 	Plugin
@@ -60,6 +78,14 @@ type Syncer interface {
 	Close() error
 }
 
+// Previewer is an optional interface for sinks able to render the payloads they would send
+// for a batch without sending them. On dry runs Init is only called on sinks implementing it,
+// so their Init must not reach the destination.
+type Previewer interface {
+	Syncer
+	Preview(ctx context.Context, batch []models.Record) (payloads []string, err error)
+}
+
 // ParseInfo parses the plugin's meta.yaml file and returns an plugin Info struct.
 func ParseInfo(text string) (info Info, err error) {
 	err = yaml.Unmarshal([]byte(text), &info)
@@ -0,0 +1,229 @@
+package plugins
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRetryAfterAttempts is the max number of times a request is sent again after a Retry-After response.
+	maxRetryAfterAttempts = 3
+	// maxRetryAfter is the longest Retry-After honored, longer waits are returned to the extractor as is.
+	maxRetryAfter = 5 * time.Minute
+)
+
+// RateLimitedExtractor is an optional interface for extractors calling remote APIs,
+// so their requests follow the rate limit set for them in the recipe.
+type RateLimitedExtractor interface {
+	Extractor
+
+	// SetRateLimiter will be called before Init with the limiter of the extractor.
+	SetRateLimiter(limiter *RateLimiter)
+}
+
+// RateLimiter limits the rate and the concurrency of the requests to a remote API,
+// and holds them back while the API asks to with a Retry-After header.
+// It is safe for concurrent use, a nil RateLimiter does not limit requests.
+type RateLimiter struct {
+	requestsPerSecond float64
+	burst             float64
+	// slots holds a value per request in flight, it is nil when the concurrency is not limited
+	slots chan struct{}
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing requestsPerSecond on average with bursts of up to burst requests,
+// and up to maxInFlight requests at the same time. A limit of 0 is not applied, and burst defaults to 1.
+func NewRateLimiter(requestsPerSecond float64, burst, maxInFlight int) *RateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	l := &RateLimiter{
+		requestsPerSecond: requestsPerSecond,
+		burst:             float64(burst),
+		tokens:            float64(burst),
+		last:              time.Now(),
+	}
+	if maxInFlight > 0 {
+		l.slots = make(chan struct{}, maxInFlight)
+	}
+
+	return l
+}
+
+// Wait blocks until a request can be made or ctx is done. Release has to be called once the request is done.
+func (l *RateLimiter) Wait(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	release = func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-l.slots }
+	}
+
+	for {
+		delay := l.reserve()
+		if delay <= 0 {
+			return release, nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Pause holds back requests until the given time, such as the time given by a Retry-After header.
+func (l *RateLimiter) Pause(until time.Time) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// Transport returns a RoundTripper sending requests with base once the limiter allows them, base defaults to http.DefaultTransport.
+// A 429, 503 or 403 response with a Retry-After header pauses the limiter, and the request is sent again once the pause is over.
+func (l *RateLimiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if l == nil {
+		return base
+	}
+
+	return &rateLimitedTransport{limiter: l, base: base}
+}
+
+// Client returns a copy of client sending its requests through the Transport of the limiter,
+// a client with default settings is used when client is nil.
+func (l *RateLimiter) Client(client *http.Client) *http.Client {
+	c := &http.Client{}
+	if client != nil {
+		*c = *client
+	}
+	c.Transport = l.Transport(c.Transport)
+
+	return c
+}
+
+// reserve takes a token if the limiter is not paused and a token is available,
+// or returns how long to wait before trying again.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+	if l.requestsPerSecond <= 0 {
+		return 0
+	}
+
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.requestsPerSecond)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.requestsPerSecond * float64(time.Second))
+}
+
+type rateLimitedTransport struct {
+	limiter *RateLimiter
+	base    http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		release, err := t.limiter.Wait(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		res, err := t.base.RoundTrip(req)
+		release()
+		if err != nil {
+			return nil, err
+		}
+
+		wait, ok := retryAfter(res)
+		if !ok {
+			return res, nil
+		}
+		t.limiter.Pause(time.Now().Add(wait))
+
+		// the body of the request is needed to send it again
+		if attempt == maxRetryAfterAttempts || (req.Body != nil && req.GetBody == nil) {
+			return res, nil
+		}
+		io.Copy(ioutil.Discard, res.Body)
+		res.Body.Close()
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryAfter returns how long the server asks to wait before sending requests again,
+// false when the response does not ask to or the wait is longer than maxRetryAfter.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	// some APIs, such as GitHub for its secondary rate limits, respond with 403 and Retry-After
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusForbidden:
+	default:
+		return 0, false
+	}
+	header := res.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		wait = time.Until(date)
+	} else {
+		return 0, false
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > maxRetryAfter {
+		return 0, false
+	}
+
+	return wait, true
+}
@@ -0,0 +1,98 @@
+package plugins_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/odpf/meteor/plugins"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("should limit requests per second after the burst", func(t *testing.T) {
+		limiter := plugins.NewRateLimiter(20, 2, 0)
+
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			release, err := limiter.Wait(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			release()
+		}
+		// 2 requests of the burst, then 2 requests 50ms apart
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(90*time.Millisecond))
+	})
+
+	t.Run("should limit requests in flight", func(t *testing.T) {
+		limiter := plugins.NewRateLimiter(0, 0, 1)
+		release, err := limiter.Wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = limiter.Wait(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		release()
+		release, err = limiter.Wait(context.Background())
+		assert.NoError(t, err)
+		release()
+	})
+
+	t.Run("should send request again after retry after", func(t *testing.T) {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := plugins.NewRateLimiter(0, 0, 0).Client(nil)
+		start := time.Now()
+		res, err := client.Post(server.URL, "application/json", strings.NewReader(`{"page":1}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Second))
+	})
+
+	t.Run("should return response when retry after is too long", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		res, err := plugins.NewRateLimiter(0, 0, 0).Client(nil).Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	})
+
+	t.Run("should not limit requests of a nil limiter", func(t *testing.T) {
+		var limiter *plugins.RateLimiter
+		release, err := limiter.Wait(context.Background())
+		assert.NoError(t, err)
+		release()
+		assert.Equal(t, http.DefaultTransport, limiter.Transport(nil))
+	})
+}
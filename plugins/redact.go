@@ -0,0 +1,225 @@
+package plugins
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/odpf/meteor/secret"
+	"gopkg.in/yaml.v3"
+)
+
+// MaskedValue replaces sensitive values wherever a config is printed.
+const MaskedValue = "*****"
+
+// minMaskedLength is the min length of a sensitive value masked in text, shorter values would mask unrelated words.
+const minMaskedLength = 4
+
+// sensitiveKeyParts are the parts of config keys telling their value is sensitive,
+// for the fields that are not marked as sensitive in the config schema.
+var sensitiveKeyParts = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"api_key",
+	"apikey",
+	"credential",
+	"private_key",
+	"service_account",
+	"authorization",
+	"cookie",
+}
+
+// IsSensitiveKey returns whether the name of a config key tells its value is sensitive, such as "password" or "X-Api-Key".
+func IsSensitiveKey(key string) bool {
+	key = strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MaskConfig returns a copy of the config with its sensitive values and the passwords of its urls masked.
+// Secret references are kept, as they do not hold the secret.
+func MaskConfig(config map[string]interface{}, schema *Schema) map[string]interface{} {
+	if config == nil {
+		return nil
+	}
+
+	return maskValue(config, schema, false).(map[string]interface{})
+}
+
+func maskValue(value interface{}, schema *Schema, sensitive bool) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" || secret.HasReference(v) {
+			return v
+		}
+		if sensitive {
+			return MaskedValue
+		}
+		return MaskURL(v)
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			masked[key] = maskValue(item, schema.property(key), sensitive || schema.isSensitive(key))
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = maskValue(item, schema.items(), sensitive)
+		}
+		return masked
+	default:
+		if sensitive {
+			return MaskedValue
+		}
+		return v
+	}
+}
+
+// SensitiveValues returns the sensitive string values of the config and the passwords of its urls,
+// so they can be masked in text such as error messages.
+func SensitiveValues(config map[string]interface{}, schema *Schema) (values []string) {
+	var collect func(value interface{}, schema *Schema, sensitive bool)
+	collect = func(value interface{}, schema *Schema, sensitive bool) {
+		switch v := value.(type) {
+		case string:
+			if sensitive {
+				values = append(values, v)
+			} else if password, ok := urlPassword(v); ok {
+				values = append(values, password)
+			}
+		case map[string]interface{}:
+			for key, item := range v {
+				collect(item, schema.property(key), sensitive || schema.isSensitive(key))
+			}
+		case []interface{}:
+			for _, item := range v {
+				collect(item, schema.items(), sensitive)
+			}
+		}
+	}
+	collect(config, schema, false)
+
+	return values
+}
+
+// MaskString returns s with each of the values replaced by MaskedValue, values too short to be told apart are left out.
+func MaskString(s string, values []string) string {
+	sorted := make([]string, 0, len(values))
+	for _, v := range values {
+		if len(v) >= minMaskedLength {
+			sorted = append(sorted, v)
+		}
+	}
+	// longer values first, so a value containing another one is masked as a whole
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+
+	for _, v := range sorted {
+		s = strings.ReplaceAll(s, v, MaskedValue)
+	}
+	return s
+}
+
+// MaskURL masks the password of a url, values that are not urls with a password are returned as they are.
+func MaskURL(value string) string {
+	password, ok := urlPassword(value)
+	if !ok {
+		return value
+	}
+
+	return strings.Replace(value, ":"+password+"@", ":"+MaskedValue+"@", 1)
+}
+
+// urlPassword returns the password of a url as it is written in the url.
+func urlPassword(value string) (string, bool) {
+	i := strings.Index(value, "://")
+	if i < 0 {
+		return "", false
+	}
+	authority := value[i+len("://"):]
+	if j := strings.IndexAny(authority, "/?#"); j >= 0 {
+		authority = authority[:j]
+	}
+	at := strings.LastIndex(authority, "@")
+	if at < 0 {
+		return "", false
+	}
+	userInfo := authority[:at]
+	colon := strings.Index(userInfo, ":")
+	if colon < 0 || colon == len(userInfo)-1 {
+		return "", false
+	}
+
+	return userInfo[colon+1:], true
+}
+
+// MaskConfigNode replaces the sensitive scalar values of the yaml node of a config with the value returned
+// by replace for their dotted key, it returns whether a value was replaced.
+// Empty values and secret references are kept.
+func MaskConfigNode(node *yaml.Node, schema *Schema, replace func(key string) string) bool {
+	return maskNode("", node, schema, false, replace)
+}
+
+func maskNode(key string, node *yaml.Node, schema *Schema, sensitive bool, replace func(key string) string) (replaced bool) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name := node.Content[i].Value
+			if maskNode(joinKey(key, name), node.Content[i+1], schema.property(name), sensitive || schema.isSensitive(name), replace) {
+				replaced = true
+			}
+		}
+	case yaml.ScalarNode:
+		if !sensitive || node.Value == "" || node.Tag == "!!null" || secret.HasReference(node.Value) {
+			return false
+		}
+		*node = yaml.Node{
+			Kind:        yaml.ScalarNode,
+			Tag:         "!!str",
+			Style:       yaml.DoubleQuotedStyle,
+			Value:       replace(key),
+			LineComment: node.LineComment,
+		}
+		return true
+	}
+
+	return replaced
+}
+
+// isSensitive returns whether the value of the property is sensitive, by its schema or by its name.
+func (s *Schema) isSensitive(key string) bool {
+	if prop := s.property(key); prop != nil && prop.Sensitive {
+		return true
+	}
+
+	return IsSensitiveKey(key)
+}
+
+func (s *Schema) property(key string) *Schema {
+	if s == nil {
+		return nil
+	}
+	if prop, ok := s.Properties[key]; ok {
+		return prop
+	}
+
+	return s.AdditionalProperties
+}
+
+func (s *Schema) items() *Schema {
+	if s == nil {
+		return nil
+	}
+
+	return s.Items
+}
@@ -0,0 +1,250 @@
+package plugins
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSON Schema types of a config field
+const (
+	SchemaTypeObject  = "object"
+	SchemaTypeArray   = "array"
+	SchemaTypeString  = "string"
+	SchemaTypeInteger = "integer"
+	SchemaTypeNumber  = "number"
+	SchemaTypeBoolean = "boolean"
+)
+
+// maxSuggestionDistance is the max number of edits between an unknown field and the field it is suggested to be
+const maxSuggestionDistance = 2
+
+// Schema is the JSON Schema of the config of a plugin, or of one of its fields.
+// Fields without a type accept any value.
+type Schema struct {
+	Type        string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Description string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Default     interface{}        `json:"default,omitempty" yaml:"default,omitempty"`
+	Items       *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	// Sensitive fields, such as passwords, are masked wherever the config is printed.
+	Sensitive bool `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+	// AdditionalProperties is the schema of the values of an object keyed by any name,
+	// objects without it only accept their properties.
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+}
+
+// SchemaError is a violation of the config schema by a field of a recipe, at the position of the field in the recipe.
+// Unknown fields are only warnings, as plugins ignore them.
+type SchemaError struct {
+	Key     string
+	Message string
+	Line    int
+	Column  int
+	Warning bool
+}
+
+func (err SchemaError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", err.Line, err.Column, err.Message)
+}
+
+// ValidateNode returns the violations of the schema by the yaml node of a config, ordered by position.
+func (s *Schema) ValidateNode(node *yaml.Node) (errs []SchemaError) {
+	errs = s.validateNode("", node)
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+
+	return errs
+}
+
+func (s *Schema) validateNode(key string, node *yaml.Node) (errs []SchemaError) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	// a null value leaves the field unset
+	if s == nil || node.Tag == "!!null" {
+		return nil
+	}
+	if !s.matchesType(node) {
+		return []SchemaError{newSchemaError(key, node, fmt.Sprintf("\"%s\" must be %s, got %s", displayKey(key), withArticle(s.Type), nodeType(node)))}
+	}
+	if len(s.Enum) > 0 && !s.inEnum(node.Value) {
+		return []SchemaError{newSchemaError(key, node, fmt.Sprintf("\"%s\" must be one of %s, got \"%s\"", displayKey(key), s.enumValues(), node.Value))}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if s.Type != SchemaTypeObject {
+			return nil
+		}
+		present := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			name, fieldKey := keyNode.Value, joinKey(key, keyNode.Value)
+			present[name] = true
+			if prop, ok := s.Properties[name]; ok {
+				errs = append(errs, prop.validateNode(fieldKey, valueNode)...)
+				continue
+			}
+			if s.AdditionalProperties != nil {
+				errs = append(errs, s.AdditionalProperties.validateNode(fieldKey, valueNode)...)
+				continue
+			}
+			err := newSchemaError(fieldKey, keyNode, fmt.Sprintf("unknown config \"%s\"", displayKey(fieldKey)))
+			if suggestion := s.closestProperty(name); suggestion != "" {
+				err.Message += fmt.Sprintf(", did you mean \"%s\"?", suggestion)
+			}
+			err.Warning = true
+			errs = append(errs, err)
+		}
+		for _, name := range s.Required {
+			if !present[name] {
+				errs = append(errs, newSchemaError(joinKey(key, name), node, fmt.Sprintf("missing required config \"%s\"", displayKey(joinKey(key, name)))))
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			errs = append(errs, s.Items.validateNode(fmt.Sprintf("%s[%d]", key, i), item)...)
+		}
+	}
+
+	return errs
+}
+
+func (s *Schema) matchesType(node *yaml.Node) bool {
+	switch s.Type {
+	case SchemaTypeObject:
+		return node.Kind == yaml.MappingNode
+	case SchemaTypeArray:
+		return node.Kind == yaml.SequenceNode
+	case SchemaTypeString:
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!str"
+	case SchemaTypeInteger:
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!int"
+	case SchemaTypeNumber:
+		return node.Kind == yaml.ScalarNode && (node.Tag == "!!int" || node.Tag == "!!float")
+	case SchemaTypeBoolean:
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+	default:
+		return true
+	}
+}
+
+func (s *Schema) inEnum(value string) bool {
+	for _, v := range s.Enum {
+		if fmt.Sprint(v) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) enumValues() string {
+	var values []string
+	for _, v := range s.Enum {
+		values = append(values, fmt.Sprint(v))
+	}
+	return strings.Join(values, ", ")
+}
+
+// closestProperty returns the property the name is most likely a misspelling of, if any.
+func (s *Schema) closestProperty(name string) (closest string) {
+	props := make([]string, 0, len(s.Properties))
+	for prop := range s.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	best := maxSuggestionDistance + 1
+	for _, prop := range props {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(prop)); d < best {
+			best, closest = d, prop
+		}
+	}
+	return closest
+}
+
+func newSchemaError(key string, node *yaml.Node, message string) SchemaError {
+	return SchemaError{Key: key, Message: message, Line: node.Line, Column: node.Column}
+}
+
+func joinKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func displayKey(key string) string {
+	if key == "" {
+		return "config"
+	}
+	return key
+}
+
+func withArticle(schemaType string) string {
+	switch schemaType {
+	case SchemaTypeObject, SchemaTypeArray, SchemaTypeInteger:
+		return "an " + schemaType
+	default:
+		return "a " + schemaType
+	}
+}
+
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return SchemaTypeObject
+	case yaml.SequenceNode:
+		return SchemaTypeArray
+	}
+	switch node.Tag {
+	case "!!int":
+		return SchemaTypeInteger
+	case "!!float":
+		return SchemaTypeNumber
+	case "!!bool":
+		return SchemaTypeBoolean
+	default:
+		return SchemaTypeString
+	}
+}
+
+// editDistance returns the number of single character edits turning a into b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
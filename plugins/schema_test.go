@@ -0,0 +1,75 @@
+package plugins_test
+
+import (
+	"testing"
+
+	"github.com/odpf/meteor/plugins"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+var configSchema = &plugins.Schema{
+	Type: plugins.SchemaTypeObject,
+	Properties: map[string]*plugins.Schema{
+		"host":    {Type: plugins.SchemaTypeString},
+		"port":    {Type: plugins.SchemaTypeInteger},
+		"format":  {Type: plugins.SchemaTypeString, Enum: []interface{}{"json", "avro"}},
+		"headers": {Type: plugins.SchemaTypeObject, AdditionalProperties: &plugins.Schema{Type: plugins.SchemaTypeString}},
+		"tables":  {Type: plugins.SchemaTypeArray, Items: &plugins.Schema{Type: plugins.SchemaTypeString}},
+	},
+	Required: []string{"host"},
+}
+
+func TestSchemaValidateNode(t *testing.T) {
+	t.Run("should return no error for valid config", func(t *testing.T) {
+		node := configNode(t, `
+port: 5432
+host: localhost
+format: avro
+headers:
+  X-Header: value
+tables: [orders, users]
+`)
+
+		assert.Empty(t, configSchema.ValidateNode(node))
+	})
+
+	t.Run("should return violations with their position", func(t *testing.T) {
+		node := configNode(t, `
+port: "5432"
+format: xml
+headers:
+  X-Header: 1
+tables: [orders, 2]
+`)
+
+		assert.Equal(t, []plugins.SchemaError{
+			{Key: "host", Message: "missing required config \"host\"", Line: 2, Column: 1},
+			{Key: "port", Message: "\"port\" must be an integer, got string", Line: 2, Column: 7},
+			{Key: "format", Message: "\"format\" must be one of json, avro, got \"xml\"", Line: 3, Column: 9},
+			{Key: "headers.X-Header", Message: "\"headers.X-Header\" must be a string, got integer", Line: 5, Column: 13},
+			{Key: "tables[1]", Message: "\"tables[1]\" must be a string, got integer", Line: 6, Column: 18},
+		}, configSchema.ValidateNode(node))
+	})
+
+	t.Run("should return warning for unknown config with the closest known one", func(t *testing.T) {
+		node := configNode(t, `
+host: localhost
+prot: 5432
+extra: true
+`)
+
+		assert.Equal(t, []plugins.SchemaError{
+			{Key: "prot", Message: "unknown config \"prot\", did you mean \"port\"?", Line: 3, Column: 1, Warning: true},
+			{Key: "extra", Message: "unknown config \"extra\"", Line: 4, Column: 1, Warning: true},
+		}, configSchema.ValidateNode(node))
+	})
+}
+
+func configNode(t *testing.T, config string) *yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(config), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc.Content[0]
+}
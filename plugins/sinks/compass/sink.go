@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/odpf/meteor/models"
@@ -59,6 +60,7 @@ func (s *Sink) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Send metadata to compass http service",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"http", "sink"},
 	}
@@ -79,6 +81,13 @@ func (s *Sink) Init(ctx context.Context, configMap map[string]interface{}) (err
 func (s *Sink) Sink(ctx context.Context, batch []models.Record) (err error) {
 	for _, record := range batch {
 		metadata := record.Data()
+		if models.IsTombstone(record) {
+			if err = s.delete(metadata.GetResource().GetUrn()); err != nil {
+				return errors.Wrap(err, "error deleting asset")
+			}
+			s.logger.Info("successfully deleted asset from compass", "record", metadata.GetResource().Urn)
+			continue
+		}
 		s.logger.Info("sinking record to compass", "record", metadata.GetResource().Urn)
 
 		compassPayload, err := s.buildCompassPayload(metadata)
@@ -95,6 +104,27 @@ func (s *Sink) Sink(ctx context.Context, batch []models.Record) (err error) {
 	return
 }
 
+// Preview renders the requests the sink would send to compass for the batch.
+func (s *Sink) Preview(ctx context.Context, batch []models.Record) (payloads []string, err error) {
+	for _, record := range batch {
+		if models.IsTombstone(record) {
+			payloads = append(payloads, fmt.Sprintf("%s %s", http.MethodDelete, s.assetURL(record.Data().GetResource().GetUrn())))
+			continue
+		}
+		compassPayload, err := s.buildCompassPayload(record.Data())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build compass payload")
+		}
+		payloadBytes, err := json.Marshal(compassPayload)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode compass payload")
+		}
+		payloads = append(payloads, fmt.Sprintf("%s %s\n%s", http.MethodPatch, s.assetsURL(), payloadBytes))
+	}
+
+	return
+}
+
 func (s *Sink) Close() (err error) { return }
 
 func (s *Sink) send(record RequestPayload) (err error) {
@@ -104,12 +134,26 @@ func (s *Sink) send(record RequestPayload) (err error) {
 	}
 
 	// send request
-	url := fmt.Sprintf("%s/v1beta1/assets", s.config.Host)
-	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequest(http.MethodPatch, s.assetsURL(), bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return
 	}
 
+	return s.do(req, http.StatusOK)
+}
+
+// delete removes the asset with the urn from compass, assets compass does not have are already deleted.
+func (s *Sink) delete(urn string) (err error) {
+	req, err := http.NewRequest(http.MethodDelete, s.assetURL(urn), nil)
+	if err != nil {
+		return
+	}
+
+	return s.do(req, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+// do sends the request with the configured headers, and fails on a response status other than the expected ones.
+func (s *Sink) do(req *http.Request, expectedStatus ...int) (err error) {
 	for hdrKey, hdrVal := range s.config.Headers {
 		hdrVals := strings.Split(hdrVal, ",")
 		for _, val := range hdrVals {
@@ -119,10 +163,13 @@ func (s *Sink) send(record RequestPayload) (err error) {
 
 	res, err := s.client.Do(req)
 	if err != nil {
-		return
+		return plugins.NewRetryError(err)
 	}
-	if res.StatusCode == 200 {
-		return
+	defer res.Body.Close()
+	for _, status := range expectedStatus {
+		if res.StatusCode == status {
+			return
+		}
 	}
 
 	var bodyBytes []byte
@@ -132,12 +179,15 @@ func (s *Sink) send(record RequestPayload) (err error) {
 	}
 	err = fmt.Errorf("compass returns %d: %v", res.StatusCode, string(bodyBytes))
 
-	switch code := res.StatusCode; {
-	case code >= 500:
-		return plugins.NewRetryError(err)
-	default:
-		return err
-	}
+	return plugins.NewStatusError(res.StatusCode, err)
+}
+
+func (s *Sink) assetsURL() string {
+	return fmt.Sprintf("%s/v1beta1/assets", s.config.Host)
+}
+
+func (s *Sink) assetURL(urn string) string {
+	return fmt.Sprintf("%s/%s", s.assetsURL(), url.PathEscape(urn))
 }
 
 func (s *Sink) buildCompassPayload(metadata models.Metadata) (RequestPayload, error) {
@@ -74,6 +74,34 @@ func TestSink(t *testing.T) {
 		assert.Equal(t, errMessage, err.Error())
 	})
 
+	t.Run("should delete asset of tombstone record", func(t *testing.T) {
+		for _, code := range []int{204, 404} {
+			t.Run(fmt.Sprintf("%d status code", code), func(t *testing.T) {
+				client := newMockHTTPClient(map[string]interface{}{}, http.MethodDelete, url, compass.RequestPayload{})
+				client.SetupResponse(code, "")
+				ctx := context.TODO()
+
+				compassSink := compass.New(client, testUtils.Logger)
+				err := compassSink.Init(ctx, map[string]interface{}{
+					"host": host,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				data := &assetsv1beta1.Topic{
+					Resource: &commonv1beta1.Resource{Urn: "kafka::broker/my-topic"},
+					Event:    &commonv1beta1.Event{Action: models.EventActionDelete},
+				}
+				err = compassSink.Sink(ctx, []models.Record{models.NewRecord(data)})
+				assert.NoError(t, err)
+
+				assert.Equal(t, http.MethodDelete, client.req.Method)
+				assert.Equal(t, url+"/kafka::broker%2Fmy-topic", client.req.URL.String())
+			})
+		}
+	})
+
 	t.Run("should return RetryError if compass returns certain status code", func(t *testing.T) {
 		for _, code := range []int{500, 501, 502, 503, 504, 505} {
 			t.Run(fmt.Sprintf("%d status code", code), func(t *testing.T) {
@@ -97,6 +125,30 @@ func TestSink(t *testing.T) {
 		}
 	})
 
+	t.Run("should return PermanentError if compass rejects the request", func(t *testing.T) {
+		for _, code := range []int{400, 401, 403, 404, 422} {
+			t.Run(fmt.Sprintf("%d status code", code), func(t *testing.T) {
+				url := fmt.Sprintf("%s/v1beta1/assets", host)
+				client := newMockHTTPClient(map[string]interface{}{}, http.MethodPatch, url, compass.RequestPayload{})
+				client.SetupResponse(code, `{"reason":"bad request"}`)
+				ctx := context.TODO()
+
+				compassSink := compass.New(client, testUtils.Logger)
+				err := compassSink.Init(ctx, map[string]interface{}{
+					"host": host,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				data := &assetsv1beta1.Topic{Resource: &commonv1beta1.Resource{}}
+				err = compassSink.Sink(ctx, []models.Record{models.NewRecord(data)})
+				assert.True(t, errors.Is(err, plugins.PermanentError{}))
+				assert.False(t, errors.Is(err, plugins.RetryError{}))
+			})
+		}
+	})
+
 	t.Run("should return error for various invalid labels", func(t *testing.T) {
 		testData := &assetsv1beta1.User{
 			Resource: &commonv1beta1.Resource{
@@ -469,6 +521,29 @@ func TestSink(t *testing.T) {
 	}
 }
 
+func TestPreview(t *testing.T) {
+	t.Run("should render requests without sending them", func(t *testing.T) {
+		client := newMockHTTPClient(map[string]interface{}{}, http.MethodPatch, url, compass.RequestPayload{})
+		ctx := context.TODO()
+
+		compassSink := compass.New(client, testUtils.Logger)
+		err := compassSink.Init(ctx, map[string]interface{}{
+			"host": host,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data := &assetsv1beta1.Topic{Resource: &commonv1beta1.Resource{Urn: "my-topic-urn", Type: "topic"}}
+		payloads, err := compassSink.(plugins.Previewer).Preview(ctx, []models.Record{models.NewRecord(data)})
+		assert.NoError(t, err)
+		assert.Len(t, payloads, 1)
+		assert.True(t, strings.HasPrefix(payloads[0], fmt.Sprintf("%s %s\n", http.MethodPatch, url)))
+		assert.Contains(t, payloads[0], `"urn":"my-topic-urn"`)
+		assert.Nil(t, client.req)
+	})
+}
+
 type mockHTTPClient struct {
 	URL            string
 	Method         string
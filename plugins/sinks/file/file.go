@@ -45,6 +45,7 @@ func (s *Sink) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "save output to a file",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"file", "json", "yaml", "sink"},
 	}
@@ -95,8 +96,7 @@ func (s *Sink) Sink(ctx context.Context, batch []models.Record) (err error) {
 }
 
 func (s *Sink) Close() (err error) {
-	// return s.File.Close()
-	return nil
+	return s.File.Close()
 }
 
 func (s *Sink) ndjsonOut(data []models.Metadata) error {
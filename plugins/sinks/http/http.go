@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/odpf/meteor/models"
@@ -22,10 +23,12 @@ import (
 var summary string
 
 type Config struct {
-	URL         string            `mapstructure:"url" validate:"required"`
-	Headers     map[string]string `mapstructure:"headers"`
-	Method      string            `mapstructure:"method" validate:"required"`
-	SuccessCode int               `mapstructure:"success_code" default:"200"`
+	URL          string            `mapstructure:"url" validate:"required"`
+	Headers      map[string]string `mapstructure:"headers"`
+	Method       string            `mapstructure:"method" validate:"required"`
+	SuccessCode  int               `mapstructure:"success_code" default:"200"`
+	DeleteURL    string            `mapstructure:"delete_url"`
+	DeleteMethod string            `mapstructure:"delete_method" default:"DELETE"`
 }
 
 var sampleConfig = `
@@ -34,6 +37,8 @@ url: https://compass.com/route
 # Additional HTTP headers, multiple headers value are separated by a comma
 headers:
 	X-Other-Header: value1, value2
+# The url deleted assets are sent to, {urn} is replaced by the urn of the asset
+delete_url: https://compass.com/route/{urn}
 `
 
 type httpClient interface {
@@ -55,13 +60,20 @@ func (s *Sink) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Send metadata to http service",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"http", "sink"},
 	}
 }
 
 func (s *Sink) Validate(configMap map[string]interface{}) (err error) {
-	return utils.BuildConfig(configMap, &s.config)
+	if err = utils.BuildConfig(configMap, &s.config); err != nil {
+		return
+	}
+	if s.config.DeleteURL != "" && !strings.Contains(s.config.DeleteURL, "{urn}") {
+		return errors.New("delete_url has to contain {urn}")
+	}
+	return
 }
 
 func (s *Sink) Init(ctx context.Context, configMap map[string]interface{}) (err error) {
@@ -74,6 +86,17 @@ func (s *Sink) Init(ctx context.Context, configMap map[string]interface{}) (err
 func (s *Sink) Sink(ctx context.Context, batch []models.Record) (err error) {
 	for _, record := range batch {
 		metadata := record.Data()
+		if models.IsTombstone(record) {
+			if s.config.DeleteURL == "" {
+				s.logger.Warn("skipping deleted asset, delete_url is not set", "record", metadata.GetResource().Urn)
+				continue
+			}
+			if err = s.delete(metadata.GetResource().GetUrn()); err != nil {
+				return errors.Wrap(err, "error deleting asset")
+			}
+			s.logger.Info("successfully deleted asset from http", "record", metadata.GetResource().Urn)
+			continue
+		}
 		s.logger.Info("sinking record to http", "record", metadata.GetResource().Urn)
 		payload, err := json.Marshal(metadata)
 		if err != nil {
@@ -89,6 +112,26 @@ func (s *Sink) Sink(ctx context.Context, batch []models.Record) (err error) {
 	return
 }
 
+// Preview renders the requests the sink would send for the batch.
+func (s *Sink) Preview(ctx context.Context, batch []models.Record) (payloads []string, err error) {
+	for _, record := range batch {
+		if models.IsTombstone(record) {
+			if s.config.DeleteURL == "" {
+				continue
+			}
+			payloads = append(payloads, fmt.Sprintf("%s %s", s.config.DeleteMethod, s.deleteURL(record.Data().GetResource().GetUrn())))
+			continue
+		}
+		payload, err := json.Marshal(record.Data())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build http payload")
+		}
+		payloads = append(payloads, fmt.Sprintf("%s %s\n%s", s.config.Method, s.config.URL, payload))
+	}
+
+	return
+}
+
 func (s *Sink) Close() (err error) { return }
 
 func (s *Sink) send(payloadBytes []byte) (err error) {
@@ -98,6 +141,26 @@ func (s *Sink) send(payloadBytes []byte) (err error) {
 		return
 	}
 
+	return s.do(req, s.config.SuccessCode)
+}
+
+// delete sends the deletion of the asset with the urn, assets the service does not have are already deleted.
+func (s *Sink) delete(urn string) (err error) {
+	req, err := http.NewRequest(s.config.DeleteMethod, s.deleteURL(urn), nil)
+	if err != nil {
+		return
+	}
+
+	return s.do(req, s.config.SuccessCode, http.StatusNoContent, http.StatusNotFound)
+}
+
+// deleteURL returns the url the deletion of the asset is sent to.
+func (s *Sink) deleteURL(urn string) string {
+	return strings.ReplaceAll(s.config.DeleteURL, "{urn}", url.PathEscape(urn))
+}
+
+// do sends the request with the configured headers, and fails on a response status other than the expected ones.
+func (s *Sink) do(req *http.Request, expectedStatus ...int) (err error) {
 	for hdrKey, hdrVal := range s.config.Headers {
 		hdrVals := strings.Split(hdrVal, ",")
 		for _, val := range hdrVals {
@@ -107,10 +170,13 @@ func (s *Sink) send(payloadBytes []byte) (err error) {
 
 	res, err := s.client.Do(req)
 	if err != nil {
-		return
+		return plugins.NewRetryError(err)
 	}
-	if res.StatusCode == s.config.SuccessCode {
-		return
+	defer res.Body.Close()
+	for _, status := range expectedStatus {
+		if res.StatusCode == status {
+			return
+		}
 	}
 
 	var bodyBytes []byte
@@ -120,12 +186,7 @@ func (s *Sink) send(payloadBytes []byte) (err error) {
 	}
 	err = fmt.Errorf("http returns %d: %v", res.StatusCode, string(bodyBytes))
 
-	switch code := res.StatusCode; {
-	case code >= 500:
-		return plugins.NewRetryError(err)
-	default:
-		return err
-	}
+	return plugins.NewStatusError(res.StatusCode, err)
 }
 
 func init() {
@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/alecthomas/assert"
@@ -39,6 +40,17 @@ func TestSink(t *testing.T) {
 		assert.Equal(t, err, plugins.InvalidConfigError{Type: plugins.PluginTypeSink, PluginName: "http"})
 	})
 
+	t.Run("should return error when delete url does not contain urn", func(t *testing.T) {
+		httpSink := h.New(&http.Client{}, testutils.Logger)
+		config := map[string]interface{}{
+			"url":        "http://sitename.com/assets",
+			"method":     "POST",
+			"delete_url": "http://sitename.com/assets",
+		}
+		err := httpSink.Init(context.TODO(), config)
+		assert.Equal(t, err, plugins.InvalidConfigError{Type: plugins.PluginTypeSink, PluginName: "http"})
+	})
+
 	t.Run("should return no error for valid config, without optional values", func(t *testing.T) {
 		httpSink := h.New(&http.Client{}, testutils.Logger)
 		config := map[string]interface{}{
@@ -153,6 +165,57 @@ func TestSink(t *testing.T) {
 		err = httpSink.Sink(context.TODO(), getExpectedVal())
 		assert.NoError(t, err)
 	})
+
+	t.Run("should send deletion to delete url for tombstone record", func(t *testing.T) {
+		var method, path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method, path = r.Method, r.URL.EscapedPath()
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		httpSink := h.New(server.Client(), testutils.Logger)
+		err := httpSink.Init(context.TODO(), map[string]interface{}{
+			"url":        server.URL + "/assets",
+			"method":     "POST",
+			"delete_url": server.URL + "/assets/{urn}",
+		})
+		assert.NoError(t, err)
+
+		err = httpSink.Sink(context.TODO(), []models.Record{
+			models.NewRecord(&assetsv1beta1.Table{
+				Resource: &commonv1beta1.Resource{Urn: "elasticsearch/index1"},
+				Event:    &commonv1beta1.Event{Action: models.EventActionDelete},
+			}),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodDelete, method)
+		assert.Equal(t, "/assets/elasticsearch%2Findex1", path)
+	})
+
+	t.Run("should skip tombstone record when delete url is not set", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+		}))
+		defer server.Close()
+
+		httpSink := h.New(server.Client(), testutils.Logger)
+		err := httpSink.Init(context.TODO(), map[string]interface{}{
+			"url":    server.URL + "/assets",
+			"method": "POST",
+		})
+		assert.NoError(t, err)
+
+		err = httpSink.Sink(context.TODO(), []models.Record{
+			models.NewRecord(&assetsv1beta1.Table{
+				Resource: &commonv1beta1.Resource{Urn: "elasticsearch/index1"},
+				Event:    &commonv1beta1.Event{Action: models.EventActionDelete},
+			}),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, requests)
+	})
 }
 
 func getExpectedVal() []models.Record {
@@ -53,6 +53,7 @@ func (s *Sink) Info() plugins.Info {
 		Description:  "Sink metadata to Apache Kafka topic",
 		Summary:      summary,
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Tags:         []string{"kafka", "topic", "sink"},
 	}
 }
@@ -73,7 +74,7 @@ func (s *Sink) Init(ctx context.Context, configMap map[string]interface{}) (err
 
 func (s *Sink) Sink(ctx context.Context, batch []models.Record) (err error) {
 	for _, record := range batch {
-		if err := s.push(ctx, record.Data()); err != nil {
+		if err := s.push(ctx, record.Data(), models.IsTombstone(record)); err != nil {
 			return err
 		}
 	}
@@ -85,10 +86,14 @@ func (s *Sink) Close() (err error) {
 	return s.writer.Close()
 }
 
-func (s *Sink) push(ctx context.Context, payload interface{}) error {
-	kafkaValue, err := s.buildValue(payload)
-	if err != nil {
-		return err
+// push writes the payload to the topic, a tombstone is written as a message with the key of the asset and no value.
+func (s *Sink) push(ctx context.Context, payload interface{}, tombstone bool) error {
+	var kafkaValue []byte
+	if !tombstone {
+		var err error
+		if kafkaValue, err = s.buildValue(payload); err != nil {
+			return err
+		}
 	}
 
 	kafkaKey, err := s.buildKey(payload, s.config.KeyPath)
@@ -103,12 +108,26 @@ func (s *Sink) push(ctx context.Context, payload interface{}) error {
 		},
 	)
 	if err != nil {
-		return errors.Wrap(err, "failed to write messages")
+		return writeError(ctx, errors.Wrap(err, "failed to write messages"))
 	}
 
 	return nil
 }
 
+// writeError classifies an error of the writer. Errors the broker reports as not temporary,
+// such as a message too large or a topic authorization failure, are permanent,
+// others such as connection errors or leader elections are retried.
+func writeError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	var kafkaErr kafka.Error
+	if errors.As(err, &kafkaErr) && !kafkaErr.Temporary() {
+		return plugins.NewPermanentError(err)
+	}
+	return plugins.NewRetryError(err)
+}
+
 func (s *Sink) buildValue(value interface{}) ([]byte, error) {
 	protoBytes, err := proto.Marshal(value.(proto.Message))
 	if err != nil {
@@ -61,6 +61,7 @@ func (s *Sink) Info() plugins.Info {
 	return plugins.Info{
 		Description:  "Send metadata to stencil http service",
 		SampleConfig: sampleConfig,
+		ConfigSchema: utils.ConfigSchema(Config{}, sampleConfig),
 		Summary:      summary,
 		Tags:         []string{"http", "sink"},
 	}
@@ -87,8 +88,9 @@ func (s *Sink) Sink(_ context.Context, batch []models.Record) (err error) {
 	for _, record := range batch {
 		metadata := record.Data()
 
+		// schemas registered in stencil are versioned, so the ones of deleted tables are kept
 		table, ok := metadata.(*assetsv1beta1.Table)
-		if !ok {
+		if !ok || models.IsTombstone(record) {
 			continue
 		}
 		s.logger.Info("sinking record to stencil", "record", table.GetResource().Urn)
@@ -168,7 +170,7 @@ func (s *Sink) send(tableURN string, record interface{}) (err error) {
 
 	res, err := s.client.Do(req)
 	if err != nil {
-		return
+		return plugins.NewRetryError(err)
 	}
 	if res.StatusCode == http.StatusCreated {
 		return
@@ -181,12 +183,7 @@ func (s *Sink) send(tableURN string, record interface{}) (err error) {
 	}
 	err = fmt.Errorf("stencil returns %d: %v", res.StatusCode, string(bodyBytes))
 
-	switch code := res.StatusCode; {
-	case code >= 500:
-		return plugins.NewRetryError(err)
-	default:
-		return err
-	}
+	return plugins.NewStatusError(res.StatusCode, err)
 }
 
 // buildJsonProperties builds the json schema properties
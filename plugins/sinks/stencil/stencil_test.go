@@ -99,6 +99,32 @@ func TestSink(t *testing.T) {
 		}
 	})
 
+	t.Run("should return PermanentError if stencil rejects the schema", func(t *testing.T) {
+		for _, code := range []int{400, 401, 403, 409} {
+			t.Run(fmt.Sprintf("%d status code", code), func(t *testing.T) {
+				url := fmt.Sprintf("%s/v1beta1/namespaces/%s/schemas/%s", host, namespaceID, tableURN)
+				client := newMockHTTPClient(map[string]interface{}{}, http.MethodPost, url, stencil.JsonSchema{})
+				client.SetupResponse(code, `{"reason":"schema is not compatible"}`)
+				ctx := context.TODO()
+
+				stencilSink := stencil.New(client, testUtils.Logger)
+				err := stencilSink.Init(ctx, map[string]interface{}{
+					"host":         host,
+					"namespace_id": namespaceID,
+					"format":       "json",
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				data := &assetsv1beta1.Table{Resource: &commonv1beta1.Resource{Urn: tableURN}}
+				err = stencilSink.Sink(ctx, []models.Record{models.NewRecord(data)})
+				assert.True(t, errors.Is(err, plugins.PermanentError{}))
+				assert.False(t, errors.Is(err, plugins.RetryError{}))
+			})
+		}
+	})
+
 	successJsonTestCases := []struct {
 		description string
 		data        *assetsv1beta1.Table
@@ -0,0 +1,17 @@
+package plugins
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/odpf/meteor/plugins"
+
+// StartSpan starts a span as a child of the span of the plugin stage in ctx.
+// The returned context has to be passed down for further spans to be its children.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
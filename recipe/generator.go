@@ -1,6 +1,7 @@
 package recipe
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path"
@@ -17,6 +18,8 @@ type TemplateConfig struct {
 	OutputDirPath    string
 	DataPath         string
 	Data             []TemplateData
+	// Schemas tell the sensitive config values of the plugins, which are replaced by environment variable references.
+	Schemas Schemas
 }
 
 func FromTemplate(config TemplateConfig) error {
@@ -31,7 +34,7 @@ func FromTemplate(config TemplateConfig) error {
 	}
 
 	for _, d := range config.Data {
-		if err := outputRecipe(tmplt, config.OutputDirPath, d); err != nil {
+		if err := outputRecipe(tmplt, config.OutputDirPath, d, config.Schemas); err != nil {
 			return fmt.Errorf("error outputing recipe: %w", err)
 		}
 	}
@@ -39,7 +42,7 @@ func FromTemplate(config TemplateConfig) error {
 	return nil
 }
 
-func outputRecipe(tmplt *template.Template, outputDir string, data TemplateData) error {
+func outputRecipe(tmplt *template.Template, outputDir string, data TemplateData, schemas Schemas) error {
 	file, err := os.Create(path.Join(outputDir, data.FileName+".yaml"))
 	if err != nil {
 		return fmt.Errorf("error creating file: %w", err)
@@ -50,9 +53,13 @@ func outputRecipe(tmplt *template.Template, outputDir string, data TemplateData)
 		data.Data["name"] = data.FileName
 	}
 
-	if err := tmplt.Execute(file, data); err != nil {
+	var buf bytes.Buffer
+	if err := tmplt.Execute(&buf, data); err != nil {
 		return fmt.Errorf("error executing template: %w", err)
 	}
+	if _, err := file.Write(maskRecipeFile(buf.Bytes(), schemas)); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
 
 	return nil
 }
@@ -103,6 +103,29 @@ func TestFromTemplate(t *testing.T) {
 			)
 		})
 
+		t.Run("when recipe has sensitive values", func(t *testing.T) {
+			bytes, err := ioutil.ReadFile("./testdata/generator/data-secrets.yaml")
+			require.NoError(t, err)
+
+			var data []recipe.TemplateData
+			require.NoError(t, yaml.Unmarshal(bytes, &data))
+
+			cleanDir(t, outputDir)
+			defer cleanDir(t, outputDir)
+
+			err = recipe.FromTemplate(recipe.TemplateConfig{
+				TemplateFilePath: "./testdata/generator/template-secrets.yaml",
+				OutputDirPath:    outputDir,
+				Data:             data,
+			})
+			require.NoError(t, err)
+
+			assertRecipeFile(t,
+				"./testdata/generator/expected-secrets.yaml",
+				path.Join(outputDir, data[0].FileName+".yaml"),
+			)
+		})
+
 	})
 }
 
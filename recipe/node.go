@@ -2,25 +2,64 @@ package recipe
 
 import (
 	"fmt"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // RecipeNode contains the json data for a recipe node
 type RecipeNode struct {
-	Name       yaml.Node    `json:"name" yaml:"name"`
-	Version    yaml.Node    `json:"version" yaml:"version"`
-	Source     PluginNode   `json:"source" yaml:"source"`
-	Sinks      []PluginNode `json:"sinks" yaml:"sinks"`
-	Processors []PluginNode `json:"processors" yaml:"processors"`
+	Name              yaml.Node       `json:"name" yaml:"name"`
+	Version           yaml.Node       `json:"version" yaml:"version"`
+	Source            PluginNode      `json:"source" yaml:"source"`
+	Sources           []PluginNode    `json:"sources" yaml:"sources"`
+	Sinks             []PluginNode    `json:"sinks" yaml:"sinks"`
+	Processors        []PluginNode    `json:"processors" yaml:"processors"`
+	DeadLetter        *DeadLetterNode `json:"dead_letter" yaml:"dead_letter"`
+	Priority          yaml.Node       `json:"priority" yaml:"priority"`
+	Group             yaml.Node       `json:"group" yaml:"group"`
+	Timeout           yaml.Node       `json:"timeout" yaml:"timeout"`
+	Schedule          yaml.Node       `json:"schedule" yaml:"schedule"`
+	ChangeDetection   yaml.Node       `json:"change_detection" yaml:"change_detection"`
+	DeletionDetection yaml.Node       `json:"deletion_detection" yaml:"deletion_detection"`
+}
+
+// DeadLetterNode contains the json data for the dead letter of a recipe
+type DeadLetterNode struct {
+	Path yaml.Node   `json:"path" yaml:"path"`
+	Sink *PluginNode `json:"sink" yaml:"sink"`
 }
 
 // PluginNode contains the json data for a recipe node that is being used for
 // generating the plugins code for a recipe.
 type PluginNode struct {
-	Name   yaml.Node            `json:"name" yaml:"name"`
-	Type   yaml.Node            `json:"type" yaml:"type"`
-	Config map[string]yaml.Node `json:"config" yaml:"config"`
+	Name       yaml.Node            `json:"name" yaml:"name"`
+	ID         yaml.Node            `json:"id" yaml:"id"`
+	Type       yaml.Node            `json:"type" yaml:"type"`
+	Config     map[string]yaml.Node `json:"config" yaml:"config"`
+	Batch      yaml.Node            `json:"batch" yaml:"batch"`
+	Timeouts   yaml.Node            `json:"timeouts" yaml:"timeouts"`
+	Retry      yaml.Node            `json:"retry" yaml:"retry"`
+	Buffer     yaml.Node            `json:"buffer" yaml:"buffer"`
+	RateLimit  yaml.Node            `json:"rate_limit" yaml:"rate_limit"`
+	OnError    yaml.Node            `json:"on_error" yaml:"on_error"`
+	Divert     *DeadLetterNode      `json:"divert" yaml:"divert"`
+	ConfigNode yaml.Node            `json:"-" yaml:"-"`
+}
+
+// UnmarshalYAML decodes the plugin node, keeping the mapping node of its config for the position of its keys.
+func (plug *PluginNode) UnmarshalYAML(value *yaml.Node) error {
+	type plainPluginNode PluginNode
+	if err := value.Decode((*plainPluginNode)(plug)); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value == "config" {
+			plug.ConfigNode = *value.Content[i+1]
+		}
+	}
+
+	return nil
 }
 
 // decodeConfig decodes the plugins config
@@ -38,6 +77,115 @@ func (plug PluginNode) decodeConfig() (map[string]interface{}, error) {
 	return config, nil
 }
 
+// decodeBatch decodes the sink batch config, it returns nil if batch is not set
+func (plug PluginNode) decodeBatch() (*BatchConfig, error) {
+	if plug.Batch.IsZero() {
+		return nil, nil
+	}
+
+	var batch BatchConfig
+	if err := plug.Batch.Decode(&batch); err != nil {
+		return nil, fmt.Errorf("error decoding batch :%w", err)
+	}
+	if batch.Size < 0 || batch.MaxBytes < 0 || batch.FlushInterval < 0 {
+		return nil, fmt.Errorf("batch limits cannot be negative")
+	}
+
+	return &batch, nil
+}
+
+// decodeTimeouts decodes the plugin timeouts, it returns nil if timeouts are not set
+func (plug PluginNode) decodeTimeouts() (*Timeouts, error) {
+	if plug.Timeouts.IsZero() {
+		return nil, nil
+	}
+
+	var timeouts Timeouts
+	if err := plug.Timeouts.Decode(&timeouts); err != nil {
+		return nil, fmt.Errorf("error decoding timeouts :%w", err)
+	}
+	if timeouts.Init < 0 || timeouts.Run < 0 {
+		return nil, fmt.Errorf("timeouts cannot be negative")
+	}
+
+	return &timeouts, nil
+}
+
+// decodeRetry decodes the sink retry policy, it returns nil if retry is not set
+func (plug PluginNode) decodeRetry() (*RetryPolicy, error) {
+	if plug.Retry.IsZero() {
+		return nil, nil
+	}
+
+	var policy RetryPolicy
+	if err := plug.Retry.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("error decoding retry :%w", err)
+	}
+	if policy.MaxAttempts < 0 || policy.InitialInterval < 0 || policy.MaxInterval < 0 {
+		return nil, fmt.Errorf("retry limits cannot be negative")
+	}
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		return nil, fmt.Errorf("retry jitter has to be between 0 and 1")
+	}
+
+	return &policy, nil
+}
+
+// decodeBuffer decodes the sink buffer config, it returns nil if buffer is not set
+func (plug PluginNode) decodeBuffer() (*BufferConfig, error) {
+	if plug.Buffer.IsZero() {
+		return nil, nil
+	}
+
+	var buffer BufferConfig
+	if err := plug.Buffer.Decode(&buffer); err != nil {
+		return nil, fmt.Errorf("error decoding buffer :%w", err)
+	}
+	if buffer.Size < 0 {
+		return nil, fmt.Errorf("buffer size cannot be negative")
+	}
+	switch buffer.OnFull {
+	case "":
+		buffer.OnFull = BufferPolicyBlock
+	case BufferPolicyBlock, BufferPolicySpill:
+	default:
+		return nil, fmt.Errorf("unknown buffer policy \"%s\", expected one of %s or %s",
+			buffer.OnFull, BufferPolicyBlock, BufferPolicySpill)
+	}
+
+	return &buffer, nil
+}
+
+// decodeRateLimit decodes the extractor rate limit, it returns nil if rate_limit is not set
+func (plug PluginNode) decodeRateLimit() (*RateLimit, error) {
+	if plug.RateLimit.IsZero() {
+		return nil, nil
+	}
+
+	var rateLimit RateLimit
+	if err := plug.RateLimit.Decode(&rateLimit); err != nil {
+		return nil, fmt.Errorf("error decoding rate limit :%w", err)
+	}
+	if rateLimit.RequestsPerSecond < 0 || rateLimit.Burst < 0 || rateLimit.MaxInFlight < 0 {
+		return nil, fmt.Errorf("rate limits cannot be negative")
+	}
+
+	return &rateLimit, nil
+}
+
+// decodeOnError decodes the processor error policy, it defaults to fail
+func (plug PluginNode) decodeOnError() (string, error) {
+	switch policy := plug.OnError.Value; policy {
+	case "":
+		return ErrorPolicyFail, nil
+	case ErrorPolicyFail, ErrorPolicySkip, ErrorPolicyDivert:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown error policy \"%s\", expected one of %s, %s or %s",
+			policy, ErrorPolicyFail, ErrorPolicySkip, ErrorPolicyDivert)
+	}
+}
+
 // toRecipe passes the value from RecipeNode to Recipe
 func (node RecipeNode) toRecipe() (recipe Recipe, err error) {
 	// It supports both tags `name` and `type` for source
@@ -45,9 +193,19 @@ func (node RecipeNode) toRecipe() (recipe Recipe, err error) {
 	if node.Source.Name.IsZero() {
 		node.Source.Name = node.Source.Type
 	}
-	sourceConfig, err := node.Source.decodeConfig()
+	var source PluginRecipe
+	if !node.Source.Name.IsZero() {
+		if source, err = node.Source.toSource(); err != nil {
+			return
+		}
+	}
+	sources, err := node.toSources()
 	if err != nil {
-		err = fmt.Errorf("error decoding source config :%w", err)
+		err = fmt.Errorf("error building sources :%w", err)
+		return
+	}
+	if len(sources) > 0 && !node.Source.Name.IsZero() {
+		err = fmt.Errorf("source and sources cannot be both set")
 		return
 	}
 	processors, err := node.toProcessors()
@@ -60,17 +218,147 @@ func (node RecipeNode) toRecipe() (recipe Recipe, err error) {
 		err = fmt.Errorf("error building sinks :%w", err)
 		return
 	}
+	deadLetter, err := node.DeadLetter.toDeadLetter()
+	if err != nil {
+		err = fmt.Errorf("error building dead letter :%w", err)
+		return
+	}
+	var priority int
+	if !node.Priority.IsZero() {
+		if err = node.Priority.Decode(&priority); err != nil {
+			err = fmt.Errorf("error decoding priority :%w", err)
+			return
+		}
+	}
+	var timeout time.Duration
+	if !node.Timeout.IsZero() {
+		if err = node.Timeout.Decode(&timeout); err != nil {
+			err = fmt.Errorf("error decoding timeout :%w", err)
+			return
+		}
+		if timeout < 0 {
+			err = fmt.Errorf("timeout cannot be negative")
+			return
+		}
+	}
+	changeDetection, err := node.decodeChangeDetection()
+	if err != nil {
+		return
+	}
+	deletionDetection, err := node.decodeDeletionDetection()
+	if err != nil {
+		return
+	}
 	recipe = Recipe{
-		Name:    node.Name.Value,
-		Version: node.Version.Value,
-		Source: PluginRecipe{
-			Name:   node.Source.Name.Value,
-			Config: sourceConfig,
-			Node:   node.Source,
-		},
-		Sinks:      sinks,
-		Processors: processors,
-		Node:       node,
+		Name:              node.Name.Value,
+		Version:           node.Version.Value,
+		Source:            source,
+		Sources:           sources,
+		Sinks:             sinks,
+		Processors:        processors,
+		DeadLetter:        deadLetter,
+		Priority:          priority,
+		Group:             node.Group.Value,
+		Timeout:           timeout,
+		Schedule:          node.Schedule.Value,
+		ChangeDetection:   changeDetection,
+		DeletionDetection: deletionDetection,
+		Node:              node,
+	}
+
+	return
+}
+
+// decodeChangeDetection decodes the change detection of the recipe, it returns nil if it is not set or not enabled
+func (node RecipeNode) decodeChangeDetection() (*ChangeDetection, error) {
+	if node.ChangeDetection.IsZero() {
+		return nil, nil
+	}
+
+	var changeDetection ChangeDetection
+	if err := node.ChangeDetection.Decode(&changeDetection); err != nil {
+		return nil, fmt.Errorf("error decoding change detection :%w", err)
+	}
+	if !changeDetection.Enabled {
+		return nil, nil
+	}
+	for _, field := range changeDetection.IgnoreFields {
+		if field == "" {
+			return nil, fmt.Errorf("change detection ignored fields cannot be empty")
+		}
+	}
+	if changeDetection.IgnoreFields == nil {
+		changeDetection.IgnoreFields = DefaultChangeIgnoredFields
+	}
+
+	return &changeDetection, nil
+}
+
+// decodeDeletionDetection decodes the deletion detection of the recipe, it returns nil if it is not set or not enabled
+func (node RecipeNode) decodeDeletionDetection() (*DeletionDetection, error) {
+	if node.DeletionDetection.IsZero() {
+		return nil, nil
+	}
+
+	var deletionDetection DeletionDetection
+	if err := node.DeletionDetection.Decode(&deletionDetection); err != nil {
+		return nil, fmt.Errorf("error decoding deletion detection :%w", err)
+	}
+	if !deletionDetection.Enabled {
+		return nil, nil
+	}
+
+	return &deletionDetection, nil
+}
+
+// toSource passes the value of source PluginNode to its PluginRecipe
+func (plug PluginNode) toSource() (source PluginRecipe, err error) {
+	config, err := plug.decodeConfig()
+	if err != nil {
+		err = fmt.Errorf("error decoding source config :%w", err)
+		return
+	}
+	timeouts, err := plug.decodeTimeouts()
+	if err != nil {
+		err = fmt.Errorf("error decoding source timeouts :%w", err)
+		return
+	}
+	rateLimit, err := plug.decodeRateLimit()
+	if err != nil {
+		err = fmt.Errorf("error decoding source rate limit :%w", err)
+		return
+	}
+
+	return PluginRecipe{
+		Name:      plug.Name.Value,
+		ID:        plug.ID.Value,
+		Config:    config,
+		Timeouts:  timeouts,
+		RateLimit: rateLimit,
+		Node:      plug,
+	}, nil
+}
+
+// toSources passes the value of each PluginNode of the sources list to its PluginRecipe,
+// sources have to be told apart by their ID, which defaults to the extractor name
+func (node RecipeNode) toSources() (sources []PluginRecipe, err error) {
+	ids := make(map[string]bool)
+	for _, plug := range node.Sources {
+		if plug.Name.IsZero() {
+			plug.Name = plug.Type
+		}
+		source, sourceErr := plug.toSource()
+		if sourceErr != nil {
+			err = sourceErr
+			return
+		}
+		id := source.SourceID()
+		if ids[id] {
+			err = fmt.Errorf("duplicate source \"%s\", set an id to tell sources of the same extractor apart", id)
+			return
+		}
+		ids[id] = true
+		sources = append(sources, source)
 	}
 
 	return
@@ -84,10 +372,28 @@ func (node RecipeNode) toProcessors() (processors []PluginRecipe, err error) {
 			err = fmt.Errorf("error decoding processor config :%w", cfgErr)
 			return
 		}
+		timeouts, timeoutsErr := processor.decodeTimeouts()
+		if timeoutsErr != nil {
+			err = fmt.Errorf("error decoding processor timeouts :%w", timeoutsErr)
+			return
+		}
+		onError, onErrorErr := processor.decodeOnError()
+		if onErrorErr != nil {
+			err = fmt.Errorf("error decoding processor on_error :%w", onErrorErr)
+			return
+		}
+		divert, divertErr := processor.Divert.toDeadLetter()
+		if divertErr != nil {
+			err = fmt.Errorf("error building processor divert :%w", divertErr)
+			return
+		}
 		processors = append(processors, PluginRecipe{
-			Name:   processor.Name.Value,
-			Config: processorConfig,
-			Node:   processor,
+			Name:     processor.Name.Value,
+			Config:   processorConfig,
+			Timeouts: timeouts,
+			OnError:  onError,
+			Divert:   divert,
+			Node:     processor,
 		})
 	}
 	return
@@ -101,11 +407,64 @@ func (node RecipeNode) toSinks() (sinks []PluginRecipe, err error) {
 			err = fmt.Errorf("error decoding sink config :%w", cfgErr)
 			return
 		}
+		batch, batchErr := sink.decodeBatch()
+		if batchErr != nil {
+			err = fmt.Errorf("error decoding sink batch :%w", batchErr)
+			return
+		}
+		timeouts, timeoutsErr := sink.decodeTimeouts()
+		if timeoutsErr != nil {
+			err = fmt.Errorf("error decoding sink timeouts :%w", timeoutsErr)
+			return
+		}
+		retry, retryErr := sink.decodeRetry()
+		if retryErr != nil {
+			err = fmt.Errorf("error decoding sink retry :%w", retryErr)
+			return
+		}
+		buffer, bufferErr := sink.decodeBuffer()
+		if bufferErr != nil {
+			err = fmt.Errorf("error decoding sink buffer :%w", bufferErr)
+			return
+		}
 		sinks = append(sinks, PluginRecipe{
+			Name:     sink.Name.Value,
+			Config:   sinkConfig,
+			Batch:    batch,
+			Timeouts: timeouts,
+			Retry:    retry,
+			Buffer:   buffer,
+			Node:     sink,
+		})
+	}
+	return
+}
+
+// toDeadLetter passes the value of DeadLetterNode to its DeadLetter, it returns nil if the node is not set
+func (node *DeadLetterNode) toDeadLetter() (deadLetter *DeadLetter, err error) {
+	if node == nil {
+		return
+	}
+
+	deadLetter = &DeadLetter{
+		Path: node.Path.Value,
+	}
+	if sink := node.Sink; sink != nil {
+		sinkConfig, cfgErr := sink.decodeConfig()
+		if cfgErr != nil {
+			err = fmt.Errorf("error decoding sink config :%w", cfgErr)
+			return
+		}
+		deadLetter.Sink = &PluginRecipe{
 			Name:   sink.Name.Value,
 			Config: sinkConfig,
-			Node:   sink,
-		})
+			Node:   *sink,
+		}
+	}
+	if (deadLetter.Path == "") == (deadLetter.Sink == nil) {
+		err = fmt.Errorf("requires either a path or a sink")
+		return
 	}
+
 	return
 }
@@ -9,14 +9,27 @@ import (
 	"text/template"
 
 	"github.com/odpf/meteor/generator"
+	"github.com/odpf/meteor/secret"
 	"github.com/odpf/salt/log"
 	"gopkg.in/yaml.v3"
 )
 
 // Reader is a struct that reads recipe files.
 type Reader struct {
-	data map[string]string
-	log  log.Logger
+	data    map[string]string
+	log     log.Logger
+	secrets *secret.Resolver
+}
+
+// ReaderOption configures a Reader.
+type ReaderOption func(*Reader)
+
+// WithSecretResolver resolves the secret references of plugin configs with the resolver,
+// references are kept as they are without it.
+func WithSecretResolver(resolver *secret.Resolver) ReaderOption {
+	return func(r *Reader) {
+		r.secrets = resolver
+	}
 }
 
 var (
@@ -24,10 +37,13 @@ var (
 )
 
 // NewReader returns a new Reader.
-func NewReader(lg log.Logger, pathToConfig string) *Reader {
+func NewReader(lg log.Logger, pathToConfig string, opts ...ReaderOption) *Reader {
 	reader := &Reader{}
 	reader.data = populateData(pathToConfig)
 	reader.log = lg
+	for _, opt := range opts {
+		opt(reader)
+	}
 	return reader
 }
 
@@ -88,6 +104,13 @@ func (r *Reader) readFile(path string) (recipe Recipe, err error) {
 		return
 	}
 
+	if r.secrets != nil {
+		err = resolveSecrets(r.secrets, &recipe)
+		if err != nil {
+			return
+		}
+	}
+
 	return
 }
 
@@ -1,19 +1,173 @@
 package recipe
 
+import (
+	"strings"
+	"time"
+)
+
 // Recipe contains the json data for a recipe
 type Recipe struct {
-	Name       string         `json:"name" yaml:"name" validate:"required"`
-	Version    string         `json:"version" yaml:"version" validate:"required"`
-	Source     PluginRecipe   `json:"source" yaml:"source" validate:"required"`
-	Sinks      []PluginRecipe `json:"sinks" yaml:"sinks" validate:"required,min=1"`
-	Processors []PluginRecipe `json:"processors" yaml:"processors"`
-	Node       RecipeNode
+	Name              string             `json:"name" yaml:"name" validate:"required"`
+	Version           string             `json:"version" yaml:"version" validate:"required"`
+	Source            PluginRecipe       `json:"source" yaml:"source"`
+	Sources           []PluginRecipe     `json:"sources,omitempty" yaml:"sources,omitempty"`
+	Sinks             []PluginRecipe     `json:"sinks" yaml:"sinks" validate:"required,min=1"`
+	Processors        []PluginRecipe     `json:"processors" yaml:"processors"`
+	DeadLetter        *DeadLetter        `json:"dead_letter,omitempty" yaml:"dead_letter,omitempty"`
+	Priority          int                `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Group             string             `json:"group,omitempty" yaml:"group,omitempty"`
+	Timeout           time.Duration      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Schedule          string             `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	ChangeDetection   *ChangeDetection   `json:"change_detection,omitempty" yaml:"change_detection,omitempty"`
+	DeletionDetection *DeletionDetection `json:"deletion_detection,omitempty" yaml:"deletion_detection,omitempty"`
+	Node              RecipeNode
+
+	// secrets are the values resolved from the secret references of the plugin configs
+	secrets []string
+}
+
+// AllSources returns the sources of the recipe, either its source or the ones of its sources list.
+func (r Recipe) AllSources() []PluginRecipe {
+	if len(r.Sources) > 0 {
+		return r.Sources
+	}
+	if r.Source.Name == "" {
+		return nil
+	}
+
+	return []PluginRecipe{r.Source}
+}
+
+// SourceNames returns the extractor names of the sources of the recipe, separated by commas.
+func (r Recipe) SourceNames() string {
+	var names []string
+	for _, src := range r.AllSources() {
+		names = append(names, src.Name)
+	}
+
+	return strings.Join(names, ",")
+}
+
+// DefaultChangeIgnoredFields are the fields left out of the content of a record by default,
+// as they change between runs without the asset changing.
+var DefaultChangeIgnoredFields = []string{"event", "profile", "preview"}
+
+// ChangeDetection contains how the records of a recipe are compared to the ones of its previous run,
+// so only new and changed records are sent to the sinks.
+type ChangeDetection struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// IgnoreFields are the paths of the fields left out of the content of a record, such as profile.usage_count,
+	// they replace DefaultChangeIgnoredFields when set.
+	IgnoreFields []string `json:"ignore_fields,omitempty" yaml:"ignore_fields,omitempty"`
+}
+
+// DeletionDetection contains whether the assets extracted by the previous run of a recipe and not extracted anymore
+// are sent to the sinks as tombstone records.
+type DeletionDetection struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+}
+
+// DeadLetter is the destination of records a sink permanently rejected.
+// Either a NDJSON file path or a sink has to be set.
+type DeadLetter struct {
+	Path string        `json:"path,omitempty" yaml:"path,omitempty"`
+	Sink *PluginRecipe `json:"sink,omitempty" yaml:"sink,omitempty"`
 }
 
 // PluginRecipe contains the json data for a recipe that is being used for
 // generating the plugins code for a recipe.
 type PluginRecipe struct {
-	Name   string                 `json:"name" yaml:"name" validate:"required"`
-	Config map[string]interface{} `json:"config" yaml:"config"`
-	Node   PluginNode
+	Name      string                 `json:"name" yaml:"name" validate:"required"`
+	ID        string                 `json:"id,omitempty" yaml:"id,omitempty"`
+	Config    map[string]interface{} `json:"config" yaml:"config"`
+	Batch     *BatchConfig           `json:"batch,omitempty" yaml:"batch,omitempty"`
+	Timeouts  *Timeouts              `json:"timeouts,omitempty" yaml:"timeouts,omitempty"`
+	Retry     *RetryPolicy           `json:"retry,omitempty" yaml:"retry,omitempty"`
+	Buffer    *BufferConfig          `json:"buffer,omitempty" yaml:"buffer,omitempty"`
+	RateLimit *RateLimit             `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+	OnError   string                 `json:"on_error,omitempty" yaml:"on_error,omitempty"`
+	Divert    *DeadLetter            `json:"divert,omitempty" yaml:"divert,omitempty"`
+	Node      PluginNode
+}
+
+// SourceID returns the ID identifying the source in its recipe, the name of its extractor when the ID is not set.
+func (p PluginRecipe) SourceID() string {
+	if p.ID != "" {
+		return p.ID
+	}
+
+	return p.Name
+}
+
+// Error policies of a processor, deciding what happens to a record the processor fails on.
+const (
+	// ErrorPolicyFail stops the run, it is the default policy.
+	ErrorPolicyFail = "fail"
+	// ErrorPolicySkip logs the error and drops the record.
+	ErrorPolicySkip = "skip"
+	// ErrorPolicyDivert drops the record and writes it with its error to the divert destination,
+	// or to the dead letter of the recipe when the processor has none.
+	ErrorPolicyDivert = "divert"
+)
+
+// Policies of a sink buffer, deciding what happens to a record when the buffer is full.
+const (
+	// BufferPolicyBlock waits for room in the buffer, slowing down the extractor and the other sinks, it is the default policy.
+	BufferPolicyBlock = "block"
+	// BufferPolicySpill writes the record to a temporary file until the sink catches up.
+	BufferPolicySpill = "spill"
+)
+
+// Timeouts contains the timeouts of a plugin, 0 means no timeout.
+type Timeouts struct {
+	// Init is the max time the plugin takes to initiate.
+	Init time.Duration `json:"init" yaml:"init"`
+	// Run is the max time the extractor takes to extract, or a sink takes to send a batch.
+	Run time.Duration `json:"run" yaml:"run"`
+}
+
+// BatchConfig contains the batching configuration of a sink.
+// A batch is sent to the sink as soon as any of the limits is reached.
+type BatchConfig struct {
+	// Size is the max number of records in a batch, 0 means no limit.
+	Size int `json:"size" yaml:"size"`
+	// MaxBytes is the max size of the records in a batch, 0 means no limit.
+	MaxBytes int `json:"max_bytes" yaml:"max_bytes"`
+	// FlushInterval is the max time a record waits in a batch, 0 means no limit.
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval"`
+}
+
+// RetryPolicy contains how a sink retries a batch it failed to send with a retryable error.
+// Fields left to 0 use the retry configuration of the agent.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of times a batch is sent, including the first attempt, 1 disables retries.
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts"`
+	// InitialInterval is the wait before the first retry, it grows exponentially with the next retries.
+	InitialInterval time.Duration `json:"initial_interval" yaml:"initial_interval"`
+	// MaxInterval is the max wait between two retries.
+	MaxInterval time.Duration `json:"max_interval" yaml:"max_interval"`
+	// Jitter randomizes each wait by up to this fraction of it, from 0 to 1, to spread retries of concurrent runs.
+	Jitter float64 `json:"jitter" yaml:"jitter"`
+}
+
+// BufferConfig contains the buffer of records waiting for a sink busy sending previous batches,
+// so a slow sink does not hold back the other sinks of the recipe.
+type BufferConfig struct {
+	// Size is the max number of records in the buffer, 0 means records are handed to the sink one at a time.
+	Size int `json:"size" yaml:"size"`
+	// OnFull is the policy applied to a record when the buffer is full, block or spill.
+	OnFull string `json:"on_full" yaml:"on_full"`
+	// SpillDir is the directory of the temporary file records are spilled to, the temp dir is used when empty.
+	SpillDir string `json:"spill_dir,omitempty" yaml:"spill_dir,omitempty"`
+}
+
+// RateLimit contains the limits of the requests an extractor makes to a remote API.
+// Limits left to 0 are not applied, Retry-After responses of the API are honored either way.
+type RateLimit struct {
+	// RequestsPerSecond is the max average number of requests sent per second.
+	RequestsPerSecond float64 `json:"requests_per_second" yaml:"requests_per_second"`
+	// Burst is the max number of requests sent at once above the average rate, it defaults to 1.
+	Burst int `json:"burst" yaml:"burst"`
+	// MaxInFlight is the max number of requests waiting for a response at the same time.
+	MaxInFlight int `json:"max_in_flight" yaml:"max_in_flight"`
 }
@@ -0,0 +1,204 @@
+package recipe
+
+import (
+	"bytes"
+
+	"github.com/odpf/meteor/plugins"
+	"github.com/odpf/meteor/secret"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaOf returns the config schema of a plugin, nil when the plugin is unknown or has no schema.
+type SchemaOf func(name string) *plugins.Schema
+
+func (s SchemaOf) of(name string) *plugins.Schema {
+	if s == nil {
+		return nil
+	}
+	return s(name)
+}
+
+// Schemas are the config schemas of the plugins a recipe is run with, telling which config values are sensitive.
+// Without a schema, values are only told sensitive by their key.
+type Schemas struct {
+	Extractor SchemaOf
+	Processor SchemaOf
+	Sink      SchemaOf
+}
+
+// Redacted returns a copy of the recipe that can be logged or printed, plugin config values resolved from secrets
+// are replaced by their secret references and sensitive values are masked.
+func (r Recipe) Redacted(schemas Schemas) Recipe {
+	redacted := r
+	if r.Source.Name != "" {
+		redacted.Source = r.Source.redacted(schemas, schemas.Extractor)
+	}
+	redacted.Sources = redactedPlugins(r.Sources, schemas, schemas.Extractor)
+	redacted.Processors = redactedPlugins(r.Processors, schemas, schemas.Processor)
+	redacted.Sinks = redactedPlugins(r.Sinks, schemas, schemas.Sink)
+	if r.DeadLetter != nil {
+		redacted.DeadLetter = r.DeadLetter.redacted(schemas)
+	}
+	// the nodes hold the config as it is written in the recipe file
+	redacted.Node = RecipeNode{Name: r.Node.Name, Version: r.Node.Version}
+
+	return redacted
+}
+
+// SensitiveValues returns the sensitive values of the plugin configs of the recipe and the secrets
+// resolved in them, so they can be masked in text such as error messages.
+func (r Recipe) SensitiveValues(schemas Schemas) (values []string) {
+	values = append(values, r.secrets...)
+	for _, src := range r.AllSources() {
+		values = append(values, plugins.SensitiveValues(src.Config, schemas.Extractor.of(src.Name))...)
+	}
+	for _, p := range r.Processors {
+		values = append(values, plugins.SensitiveValues(p.Config, schemas.Processor.of(p.Name))...)
+		if p.Divert != nil && p.Divert.Sink != nil {
+			values = append(values, plugins.SensitiveValues(p.Divert.Sink.Config, schemas.Sink.of(p.Divert.Sink.Name))...)
+		}
+	}
+	for _, s := range r.Sinks {
+		values = append(values, plugins.SensitiveValues(s.Config, schemas.Sink.of(s.Name))...)
+	}
+	if r.DeadLetter != nil && r.DeadLetter.Sink != nil {
+		values = append(values, plugins.SensitiveValues(r.DeadLetter.Sink.Config, schemas.Sink.of(r.DeadLetter.Sink.Name))...)
+	}
+
+	return values
+}
+
+func redactedPlugins(plugins []PluginRecipe, schemas Schemas, schema SchemaOf) []PluginRecipe {
+	if plugins == nil {
+		return nil
+	}
+
+	redacted := make([]PluginRecipe, len(plugins))
+	for i, p := range plugins {
+		redacted[i] = p.redacted(schemas, schema)
+	}
+	return redacted
+}
+
+func (d *DeadLetter) redacted(schemas Schemas) *DeadLetter {
+	redacted := *d
+	if d.Sink != nil {
+		sink := d.Sink.redacted(schemas, schemas.Sink)
+		redacted.Sink = &sink
+	}
+	return &redacted
+}
+
+func (p PluginRecipe) redacted(schemas Schemas, schema SchemaOf) PluginRecipe {
+	redacted := p
+	redacted.Node = PluginNode{Name: p.Node.Name, ID: p.Node.ID}
+	if p.Divert != nil {
+		redacted.Divert = p.Divert.redacted(schemas)
+	}
+
+	config := p.Config
+	if raw, err := p.Node.decodeConfig(); err == nil && len(raw) > 0 {
+		config = make(map[string]interface{}, len(p.Config))
+		for key, value := range p.Config {
+			if rawValue, ok := raw[key]; ok && hasReference(rawValue) {
+				value = rawValue
+			}
+			config[key] = value
+		}
+	}
+	redacted.Config = plugins.MaskConfig(config, schema.of(p.Name))
+
+	return redacted
+}
+
+func hasReference(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return secret.HasReference(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			if hasReference(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if hasReference(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maskRecipeFile replaces the sensitive plugin config values of a recipe file with references to environment variables,
+// so generated recipes do not hold credentials. Files that cannot be parsed are returned as they are.
+func maskRecipeFile(content []byte, schemas Schemas) []byte {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil || len(doc.Content) == 0 {
+		return content
+	}
+
+	var masked bool
+	maskPlugin := func(node *yaml.Node, schema SchemaOf) {
+		name := mappingValue(node, "name")
+		if name == nil {
+			// sources generated by meteor new are named by their type
+			name = mappingValue(node, "type")
+		}
+		config := mappingValue(node, "config")
+		if name == nil || config == nil {
+			return
+		}
+		if plugins.MaskConfigNode(config, schema.of(name.Value), func(key string) string {
+			return secret.EnvReference(name.Value, key)
+		}) {
+			masked = true
+		}
+	}
+
+	root := doc.Content[0]
+	maskPlugin(mappingValue(root, "source"), schemas.Extractor)
+	for _, node := range sequenceItems(mappingValue(root, "sources")) {
+		maskPlugin(node, schemas.Extractor)
+	}
+	for _, node := range sequenceItems(mappingValue(root, "processors")) {
+		maskPlugin(node, schemas.Processor)
+		maskPlugin(mappingValue(mappingValue(node, "divert"), "sink"), schemas.Sink)
+	}
+	for _, node := range sequenceItems(mappingValue(root, "sinks")) {
+		maskPlugin(node, schemas.Sink)
+	}
+	maskPlugin(mappingValue(mappingValue(root, "dead_letter"), "sink"), schemas.Sink)
+	if !masked {
+		return content
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return content
+	}
+	return buf.Bytes()
+}
+
+// mappingValue returns the value of the key in a mapping node, nil when the node is not a mapping or has no such key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func sequenceItems(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
@@ -0,0 +1,88 @@
+package recipe
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/odpf/meteor/secret"
+)
+
+// resolveSecrets replaces the secret references in the config values of every plugin of the recipe,
+// and keeps the resolved secrets in the recipe so they are masked whichever config value they are in.
+func resolveSecrets(resolver *secret.Resolver, rcp *Recipe) error {
+	ctx := context.Background()
+	var resolvePlugin func(p *PluginRecipe) error
+	resolvePlugin = func(p *PluginRecipe) error {
+		for key, value := range p.Config {
+			resolved, err := resolveValue(ctx, resolver, value, &rcp.secrets)
+			if err != nil {
+				return fmt.Errorf("error resolving secrets of %s config :%w", p.Name, err)
+			}
+			p.Config[key] = resolved
+		}
+		if p.Divert != nil && p.Divert.Sink != nil {
+			return resolvePlugin(p.Divert.Sink)
+		}
+		return nil
+	}
+
+	var plugins []*PluginRecipe
+	if rcp.Source.Name != "" {
+		plugins = append(plugins, &rcp.Source)
+	}
+	for i := range rcp.Sources {
+		plugins = append(plugins, &rcp.Sources[i])
+	}
+	for i := range rcp.Processors {
+		plugins = append(plugins, &rcp.Processors[i])
+	}
+	for i := range rcp.Sinks {
+		plugins = append(plugins, &rcp.Sinks[i])
+	}
+	if rcp.DeadLetter != nil && rcp.DeadLetter.Sink != nil {
+		plugins = append(plugins, rcp.DeadLetter.Sink)
+	}
+
+	for _, p := range plugins {
+		if err := resolvePlugin(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveValue resolves the secret references in the strings of a config value, including nested ones,
+// appending the resolved secrets to secrets.
+func resolveValue(ctx context.Context, resolver *secret.Resolver, value interface{}, secrets *[]string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		resolved, values, err := resolver.ResolveWithSecrets(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		*secrets = append(*secrets, values...)
+		return resolved, nil
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			r, err := resolveValue(ctx, resolver, item, secrets)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := resolveValue(ctx, resolver, item, secrets)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
@@ -27,6 +27,15 @@ func (f *ExtractorFactory) Info(name string) (plugins.Info, error) {
 	return sink.Info(), nil
 }
 
+// Schema returns the config schema of the extractor, nil when it is not registered or has no schema.
+func (f *ExtractorFactory) Schema(name string) *plugins.Schema {
+	info, err := f.Info(name)
+	if err != nil {
+		return nil
+	}
+	return info.ConfigSchema
+}
+
 // List returns a list of registered Extractors.
 func (f *ExtractorFactory) List() map[string]plugins.Info {
 	list := make(map[string]plugins.Info)
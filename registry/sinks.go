@@ -27,6 +27,15 @@ func (f *SinkFactory) Info(name string) (info plugins.Info, err error) {
 	return sink.Info(), nil
 }
 
+// Schema returns the config schema of the sink, nil when it is not registered or has no schema.
+func (f *SinkFactory) Schema(name string) *plugins.Schema {
+	info, err := f.Info(name)
+	if err != nil {
+		return nil
+	}
+	return info.ConfigSchema
+}
+
 // List returns a list of registered Sinks.
 func (f *SinkFactory) List() map[string]plugins.Info {
 	list := make(map[string]plugins.Info)
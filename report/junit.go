@@ -0,0 +1,83 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnit writes the report as a JUnit test suite with a test case per recipe.
+func writeJUnit(w io.Writer, r Report) error {
+	suite := junitTestSuite{
+		Name:     "meteor",
+		Tests:    r.Total,
+		Failures: r.Failures,
+	}
+
+	var totalMs int
+	for _, rcp := range r.Recipes {
+		totalMs += rcp.DurationInMs
+		tc := junitTestCase{
+			Name:      rcp.Name,
+			ClassName: rcp.Source,
+			Time:      junitSeconds(rcp.DurationInMs),
+			SystemOut: summary(rcp),
+		}
+		if !rcp.Success {
+			var message string
+			if len(rcp.Errors) > 0 {
+				message = rcp.Errors[0]
+			} else {
+				message = "sinks failed to send records: " + sinkSummary(rcp)
+			}
+			tc.Failure = &junitFailure{
+				Message: message,
+				Body:    strings.Join(rcp.Errors, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = junitSeconds(totalMs)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(ms int) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
@@ -0,0 +1,40 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeMarkdown writes the report as a markdown table with a row per recipe.
+func writeMarkdown(w io.Writer, r Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d failing, %d successful, and %d total\n\n", r.Failures, r.Success, r.Total)
+	b.WriteString("| Status | Recipe | Source | Duration(ms) | Records | Skipped | Sinks | Error |\n")
+	b.WriteString("| :--- | :--- | :--- | ---: | ---: | ---: | :--- | :--- |\n")
+	for _, rcp := range r.Recipes {
+		status := "success"
+		if !rcp.Success {
+			status = "failure"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %d | %d | %s | %s |\n",
+			status,
+			escapeMarkdown(rcp.Name),
+			escapeMarkdown(rcp.Source),
+			rcp.DurationInMs,
+			rcp.RecordCount,
+			rcp.SkippedCount,
+			escapeMarkdown(sinkSummary(rcp)),
+			escapeMarkdown(strings.Join(rcp.Errors, ": ")),
+		)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeMarkdown keeps a value inside its table cell.
+func escapeMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,118 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/pkg/errors"
+)
+
+// Report formats
+const (
+	FormatJSON     = "json"
+	FormatJUnit    = "junit"
+	FormatMarkdown = "markdown"
+)
+
+// Formats are the supported report formats
+var Formats = []string{FormatJSON, FormatJUnit, FormatMarkdown}
+
+// Report is the result of running a set of recipes.
+type Report struct {
+	Total    int      `json:"total"`
+	Success  int      `json:"success"`
+	Failures int      `json:"failures"`
+	Recipes  []Recipe `json:"recipes"`
+}
+
+// Recipe is the result of a single recipe run.
+type Recipe struct {
+	Name         string          `json:"name"`
+	Source       string          `json:"source"`
+	Success      bool            `json:"success"`
+	DurationInMs int             `json:"duration_in_ms"`
+	RecordCount  int             `json:"record_count"`
+	SkippedCount int             `json:"skipped_count"`
+	DroppedCount int             `json:"dropped_count"`
+	Sinks        []agent.SinkRun `json:"sinks"`
+	// Errors is the error chain of a failed run, from the outermost error to its root cause.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// New builds a report from the runs.
+func New(runs []agent.Run) Report {
+	r := Report{Total: len(runs)}
+	for _, run := range runs {
+		// records the sinks failed to send are missing from the destinations, even if the run went on
+		success := run.Success && run.SinkFailureCount() == 0
+		if success {
+			r.Success++
+		} else {
+			r.Failures++
+		}
+		r.Recipes = append(r.Recipes, Recipe{
+			Name:         run.Recipe.Name,
+			Source:       run.Recipe.SourceNames(),
+			Success:      success,
+			DurationInMs: run.DurationInMs,
+			RecordCount:  run.RecordCount,
+			SkippedCount: run.SkippedCount,
+			DroppedCount: run.DroppedCount,
+			Sinks:        run.Sinks,
+			Errors:       errorChain(run.Error),
+		})
+	}
+
+	return r
+}
+
+// Write encodes the report to w in the given format.
+func Write(w io.Writer, format string, r Report) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, r)
+	case FormatJUnit:
+		return writeJUnit(w, r)
+	case FormatMarkdown:
+		return writeMarkdown(w, r)
+	default:
+		return fmt.Errorf("unknown report format \"%s\", expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// errorChain returns the message of each error in the chain without the messages of the errors it wraps.
+func errorChain(err error) (chain []string) {
+	for err != nil {
+		msg := err.Error()
+		cause := errors.Unwrap(err)
+		if cause != nil {
+			msg = strings.TrimSuffix(msg, cause.Error())
+			msg = strings.TrimSuffix(msg, ": ")
+		}
+		// wrappers adding no message of their own, such as stack traces, are left out
+		if msg != "" {
+			chain = append(chain, msg)
+		}
+		err = cause
+	}
+
+	return
+}
+
+// summary describes the counts of a recipe run.
+func summary(rcp Recipe) string {
+	return fmt.Sprintf("records: %d, skipped: %d, dropped: %d, sinks: %s",
+		rcp.RecordCount, rcp.SkippedCount, rcp.DroppedCount, sinkSummary(rcp))
+}
+
+// sinkSummary describes the records each sink sent and failed to send.
+func sinkSummary(rcp Recipe) string {
+	sinks := make([]string, 0, len(rcp.Sinks))
+	for _, s := range rcp.Sinks {
+		sinks = append(sinks, fmt.Sprintf("%s (%d sent, %d failed)", s.Name, s.SuccessCount, s.FailureCount))
+	}
+
+	return strings.Join(sinks, ", ")
+}
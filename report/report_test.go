@@ -0,0 +1,118 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/recipe"
+	"github.com/odpf/meteor/report"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var runs = []agent.Run{
+	{
+		Recipe:       recipe.Recipe{Name: "recipe-ok", Source: recipe.PluginRecipe{Name: "bigquery"}},
+		Success:      true,
+		DurationInMs: 1500,
+		RecordCount:  10,
+		Sinks:        []agent.SinkRun{{Name: "compass", SuccessCount: 10}},
+	},
+	{
+		Recipe:       recipe.Recipe{Name: "recipe-failed", Source: recipe.PluginRecipe{Name: "tableau"}},
+		DurationInMs: 200,
+		Error: errors.Wrap(
+			errors.Wrap(errors.New("connection refused"), "error running extractor \"tableau\""),
+			"failed to run extractor",
+		),
+	},
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should count runs and split the error chain", func(t *testing.T) {
+		r := report.New(runs)
+
+		assert.Equal(t, 2, r.Total)
+		assert.Equal(t, 1, r.Success)
+		assert.Equal(t, 1, r.Failures)
+		assert.Nil(t, r.Recipes[0].Errors)
+		assert.Equal(t, []string{
+			"failed to run extractor",
+			"error running extractor \"tableau\"",
+			"connection refused",
+		}, r.Recipes[1].Errors)
+	})
+
+	t.Run("should count runs whose sinks failed to send records as failures", func(t *testing.T) {
+		r := report.New([]agent.Run{
+			{
+				Recipe:  recipe.Recipe{Name: "recipe-rejected", Source: recipe.PluginRecipe{Name: "bigquery"}},
+				Success: true,
+				Sinks:   []agent.SinkRun{{Name: "compass", SuccessCount: 8, FailureCount: 2}},
+			},
+		})
+
+		assert.Equal(t, 0, r.Success)
+		assert.Equal(t, 1, r.Failures)
+		assert.False(t, r.Recipes[0].Success)
+
+		var buf bytes.Buffer
+		require.NoError(t, report.Write(&buf, report.FormatJUnit, r))
+		assert.Contains(t, buf.String(), `message="sinks failed to send records: compass (8 sent, 2 failed)"`)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("should return error for unknown format", func(t *testing.T) {
+		err := report.Write(new(bytes.Buffer), "csv", report.New(runs))
+		assert.EqualError(t, err, "unknown report format \"csv\", expected one of json, junit, markdown")
+	})
+
+	t.Run("should write json report", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, report.Write(&buf, report.FormatJSON, report.New(runs)))
+
+		var actual report.Report
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+		assert.Equal(t, report.New(runs), actual)
+	})
+
+	t.Run("should write a junit test case per recipe", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, report.Write(&buf, report.FormatJUnit, report.New(runs)))
+
+		var actual struct {
+			Suites []struct {
+				Tests    int `xml:"tests,attr"`
+				Failures int `xml:"failures,attr"`
+				Cases    []struct {
+					Name    string `xml:"name,attr"`
+					Failure *struct {
+						Message string `xml:"message,attr"`
+					} `xml:"failure"`
+				} `xml:"testcase"`
+			} `xml:"testsuite"`
+		}
+		require.NoError(t, xml.Unmarshal(buf.Bytes(), &actual))
+		require.Len(t, actual.Suites, 1)
+		suite := actual.Suites[0]
+		assert.Equal(t, 2, suite.Tests)
+		assert.Equal(t, 1, suite.Failures)
+		assert.Equal(t, "recipe-ok", suite.Cases[0].Name)
+		assert.Nil(t, suite.Cases[0].Failure)
+		assert.Equal(t, "recipe-failed", suite.Cases[1].Name)
+		assert.Equal(t, "failed to run extractor", suite.Cases[1].Failure.Message)
+	})
+
+	t.Run("should write a markdown row per recipe", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, report.Write(&buf, report.FormatMarkdown, report.New(runs)))
+
+		assert.Contains(t, buf.String(), "| success | recipe-ok | bigquery | 1500 | 10 | 0 | compass (10 sent, 0 failed) |  |\n")
+		assert.Contains(t, buf.String(), "| failure | recipe-failed | tableau | 200 | 0 | 0 |  | failed to run extractor: error running extractor \"tableau\": connection refused |\n")
+	})
+}
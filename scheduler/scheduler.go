@@ -0,0 +1,240 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/recipe"
+	"github.com/odpf/salt/log"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrRecipeNotFound is returned when triggering a recipe that is not loaded.
+	ErrRecipeNotFound = errors.New("recipe not found")
+	// ErrRecipeRunning is returned when triggering a recipe whose previous run is still going on.
+	ErrRecipeRunning = errors.New("recipe is already running")
+	// ErrStopped is returned when triggering a recipe once the scheduler is stopped.
+	ErrStopped = errors.New("scheduler is stopped")
+)
+
+// Runner runs a recipe, it is implemented by agent.Agent.
+type Runner interface {
+	Run(ctx context.Context, rcp recipe.Recipe) agent.Run
+}
+
+// Scheduler runs recipes on their cron schedule, or when triggered.
+// A recipe is never run again while its previous run is still going on.
+type Scheduler struct {
+	runner Runner
+	logger log.Logger
+	cron   *cron.Cron
+
+	mu        sync.Mutex
+	entries   map[string]entry
+	running   map[string]bool
+	triggered sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// RecipeStatus is the status of a loaded recipe.
+type RecipeStatus struct {
+	Recipe recipe.Recipe
+	// Scheduled is false when the recipe has no valid schedule, it only runs when triggered.
+	Scheduled bool
+	Running   bool
+	// NextRun is the time of the next scheduled run, it is zero when the recipe is not scheduled
+	// or the scheduler is not started.
+	NextRun time.Time
+}
+
+// entry is a loaded recipe, id is 0 when the recipe could not be scheduled.
+type entry struct {
+	id     cron.EntryID
+	recipe recipe.Recipe
+}
+
+// New returns a Scheduler running recipes with the runner.
+func New(runner Runner, logger log.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		runner:  runner,
+		logger:  logger,
+		cron:    cron.New(),
+		entries: make(map[string]entry),
+		running: make(map[string]bool),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Load sets the recipes to schedule, replacing the ones previously loaded.
+// Recipes are matched by name, only new and changed recipes are scheduled again.
+// A run of a replaced recipe is not interrupted. Recipes without a valid schedule are not scheduled,
+// their error is only returned until they change.
+func (s *Scheduler) Load(recipes []recipe.Recipe) (errs []error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loaded := make(map[string]bool)
+	for _, rcp := range recipes {
+		if loaded[rcp.Name] {
+			errs = append(errs, fmt.Errorf("recipe \"%s\" is defined more than once", rcp.Name))
+			continue
+		}
+		loaded[rcp.Name] = true
+
+		old, exists := s.entries[rcp.Name]
+		if exists && reflect.DeepEqual(old.recipe, rcp) {
+			continue
+		}
+		s.remove(rcp.Name)
+
+		id, err := s.schedule(rcp)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		s.entries[rcp.Name] = entry{id: id, recipe: rcp}
+	}
+
+	for name := range s.entries {
+		if !loaded[name] {
+			s.remove(name)
+		}
+	}
+
+	return
+}
+
+func (s *Scheduler) schedule(rcp recipe.Recipe) (cron.EntryID, error) {
+	if rcp.Schedule == "" {
+		return 0, fmt.Errorf("recipe \"%s\" has no schedule", rcp.Name)
+	}
+
+	id, err := s.cron.AddFunc(rcp.Schedule, func() {
+		s.run(rcp)
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid schedule of recipe \"%s\"", rcp.Name)
+	}
+	s.logger.Info("scheduled recipe", "recipe", rcp.Name, "schedule", rcp.Schedule)
+
+	return id, nil
+}
+
+func (s *Scheduler) remove(name string) {
+	e, ok := s.entries[name]
+	if !ok {
+		return
+	}
+	if e.id != 0 {
+		s.cron.Remove(e.id)
+		s.logger.Info("unscheduled recipe", "recipe", name)
+	}
+	delete(s.entries, name)
+}
+
+// Recipes returns the status of the loaded recipes, sorted by name.
+func (s *Scheduler) Recipes() []RecipeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]RecipeStatus, 0, len(s.entries))
+	for name, e := range s.entries {
+		status := RecipeStatus{
+			Recipe:    e.recipe,
+			Scheduled: e.id != 0,
+			Running:   s.running[name],
+		}
+		if e.id != 0 {
+			status.NextRun = s.cron.Entry(e.id).Next
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Recipe.Name < statuses[j].Recipe.Name
+	})
+
+	return statuses
+}
+
+// Trigger starts a run of a loaded recipe now, whether it is scheduled or not.
+// It returns once the run is started, without waiting for it to finish.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return ErrStopped
+	}
+
+	e, ok := s.entries[name]
+	if !ok {
+		return ErrRecipeNotFound
+	}
+	if s.running[name] {
+		return ErrRecipeRunning
+	}
+	s.running[name] = true
+
+	s.triggered.Add(1)
+	go func() {
+		defer s.triggered.Done()
+		s.execute(e.recipe)
+	}()
+
+	return nil
+}
+
+// Start starts running the recipes on their schedule.
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops scheduling runs and cancels the running ones, giving their sinks time to drain.
+// It returns once the running recipes are done.
+func (s *Scheduler) Stop() {
+	done := s.cron.Stop()
+	// no run can be triggered once cancelled
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+	<-done.Done()
+	s.triggered.Wait()
+}
+
+// run runs the recipe unless its previous run is still going on.
+func (s *Scheduler) run(rcp recipe.Recipe) {
+	s.mu.Lock()
+	if s.running[rcp.Name] {
+		s.mu.Unlock()
+		s.logger.Warn("skipping run, previous run is still going on", "recipe", rcp.Name)
+		return
+	}
+	s.running[rcp.Name] = true
+	s.mu.Unlock()
+
+	s.execute(rcp)
+}
+
+// execute runs the recipe already marked as running.
+func (s *Scheduler) execute(rcp recipe.Recipe) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, rcp.Name)
+		s.mu.Unlock()
+	}()
+
+	run := s.runner.Run(s.ctx, rcp)
+	if run.Error != nil {
+		s.logger.Error("run failed", "recipe", rcp.Name, "error", run.Error)
+	}
+}
@@ -0,0 +1,192 @@
+package scheduler_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/recipe"
+	"github.com/odpf/meteor/scheduler"
+	"github.com/odpf/meteor/test/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerLoad(t *testing.T) {
+	t.Run("should return error for recipes that cannot be scheduled", func(t *testing.T) {
+		s := scheduler.New(new(countingRunner), utils.Logger)
+		errs := s.Load([]recipe.Recipe{
+			{Name: "valid", Schedule: "@every 1h"},
+			{Name: "no-schedule"},
+			{Name: "invalid", Schedule: "every hour"},
+			{Name: "valid", Schedule: "@every 2h"},
+		})
+
+		assert.Len(t, errs, 3)
+		assert.EqualError(t, errs[0], "recipe \"no-schedule\" has no schedule")
+		assert.Contains(t, errs[1].Error(), "invalid schedule of recipe \"invalid\"")
+		assert.EqualError(t, errs[2], "recipe \"valid\" is defined more than once")
+	})
+
+	t.Run("should only return error of an unchanged recipe once", func(t *testing.T) {
+		s := scheduler.New(new(countingRunner), utils.Logger)
+		recipes := []recipe.Recipe{{Name: "no-schedule"}}
+
+		assert.Len(t, s.Load(recipes), 1)
+		assert.Empty(t, s.Load(recipes))
+		assert.Len(t, s.Load([]recipe.Recipe{{Name: "no-schedule", Schedule: "every hour"}}), 1)
+	})
+}
+
+func TestSchedulerRun(t *testing.T) {
+	t.Run("should run recipes on their schedule", func(t *testing.T) {
+		runner := new(countingRunner)
+		s := scheduler.New(runner, utils.Logger)
+		assert.Empty(t, s.Load([]recipe.Recipe{{Name: "recipe", Schedule: "@every 1s"}}))
+
+		s.Start()
+		time.Sleep(2500 * time.Millisecond)
+		s.Stop()
+
+		// @every 1s fires at the start of each second
+		assert.GreaterOrEqual(t, runner.count("recipe"), 2)
+	})
+
+	t.Run("should not run a recipe while its previous run is going on", func(t *testing.T) {
+		runner := &countingRunner{release: make(chan struct{})}
+		s := scheduler.New(runner, utils.Logger)
+		assert.Empty(t, s.Load([]recipe.Recipe{{Name: "recipe", Schedule: "@every 1s"}}))
+
+		s.Start()
+		time.Sleep(2500 * time.Millisecond)
+		close(runner.release)
+		s.Stop()
+
+		assert.Equal(t, 1, runner.count("recipe"))
+	})
+
+	t.Run("should stop running a removed recipe", func(t *testing.T) {
+		runner := new(countingRunner)
+		s := scheduler.New(runner, utils.Logger)
+		assert.Empty(t, s.Load([]recipe.Recipe{{Name: "recipe", Schedule: "@every 1s"}}))
+		assert.Empty(t, s.Load([]recipe.Recipe{{Name: "other", Schedule: "@every 1s"}}))
+
+		s.Start()
+		time.Sleep(1500 * time.Millisecond)
+		s.Stop()
+
+		assert.Equal(t, 0, runner.count("recipe"))
+		assert.Positive(t, runner.count("other"))
+	})
+
+	t.Run("should cancel running recipes on stop", func(t *testing.T) {
+		runner := &countingRunner{waitCtx: true}
+		s := scheduler.New(runner, utils.Logger)
+		assert.Empty(t, s.Load([]recipe.Recipe{{Name: "recipe", Schedule: "@every 1s"}}))
+
+		s.Start()
+		time.Sleep(1500 * time.Millisecond)
+
+		stopped := make(chan struct{})
+		go func() {
+			s.Stop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("scheduler did not stop")
+		}
+		assert.Equal(t, 1, runner.count("recipe"))
+	})
+}
+
+func TestSchedulerTrigger(t *testing.T) {
+	t.Run("should run a loaded recipe without schedule", func(t *testing.T) {
+		runner := new(countingRunner)
+		s := scheduler.New(runner, utils.Logger)
+		s.Load([]recipe.Recipe{{Name: "recipe"}})
+
+		assert.NoError(t, s.Trigger("recipe"))
+		s.Stop()
+
+		assert.Equal(t, 1, runner.count("recipe"))
+	})
+
+	t.Run("should return error when recipe is not loaded", func(t *testing.T) {
+		s := scheduler.New(new(countingRunner), utils.Logger)
+
+		assert.Equal(t, scheduler.ErrRecipeNotFound, s.Trigger("recipe"))
+	})
+
+	t.Run("should return error when recipe is already running", func(t *testing.T) {
+		runner := &countingRunner{release: make(chan struct{})}
+		s := scheduler.New(runner, utils.Logger)
+		s.Load([]recipe.Recipe{{Name: "recipe"}})
+
+		assert.NoError(t, s.Trigger("recipe"))
+		assert.Equal(t, scheduler.ErrRecipeRunning, s.Trigger("recipe"))
+		assert.True(t, s.Recipes()[0].Running)
+
+		close(runner.release)
+		s.Stop()
+		assert.Equal(t, 1, runner.count("recipe"))
+	})
+
+	t.Run("should return error once stopped", func(t *testing.T) {
+		s := scheduler.New(new(countingRunner), utils.Logger)
+		s.Load([]recipe.Recipe{{Name: "recipe"}})
+		s.Stop()
+
+		assert.Equal(t, scheduler.ErrStopped, s.Trigger("recipe"))
+	})
+}
+
+func TestSchedulerRecipes(t *testing.T) {
+	t.Run("should return loaded recipes sorted by name", func(t *testing.T) {
+		s := scheduler.New(new(countingRunner), utils.Logger)
+		s.Load([]recipe.Recipe{
+			{Name: "b", Schedule: "@every 1h"},
+			{Name: "a"},
+		})
+
+		statuses := s.Recipes()
+		assert.Len(t, statuses, 2)
+		assert.Equal(t, "a", statuses[0].Recipe.Name)
+		assert.False(t, statuses[0].Scheduled)
+		assert.Equal(t, "b", statuses[1].Recipe.Name)
+		assert.True(t, statuses[1].Scheduled)
+	})
+}
+
+// countingRunner counts the runs of each recipe
+type countingRunner struct {
+	mu      sync.Mutex
+	runs    map[string]int
+	release chan struct{}
+	waitCtx bool
+}
+
+func (r *countingRunner) Run(ctx context.Context, rcp recipe.Recipe) agent.Run {
+	r.mu.Lock()
+	if r.runs == nil {
+		r.runs = make(map[string]int)
+	}
+	r.runs[rcp.Name]++
+	r.mu.Unlock()
+
+	if r.release != nil {
+		<-r.release
+	}
+	if r.waitCtx {
+		<-ctx.Done()
+	}
+	return agent.Run{Recipe: rcp, Success: true}
+}
+
+func (r *countingRunner) count(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runs[name]
+}
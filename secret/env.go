@@ -0,0 +1,20 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// EnvProvider reads secrets from environment variables, the path being the name of the variable.
+type EnvProvider struct{}
+
+// Get returns the value of the environment variable.
+func (EnvProvider) Get(_ context.Context, path string) (string, error) {
+	value, ok := os.LookupEnv(path)
+	if !ok {
+		return "", fmt.Errorf("environment variable \"%s\" is not set", path)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,39 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// FileProvider reads secrets from files, such as the ones of a Kubernetes secret mounted as a volume.
+type FileProvider struct {
+	dir string
+}
+
+// NewFileProvider returns a FileProvider reading paths relative to dir,
+// paths are read as they are when dir is empty.
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+// Get returns the content of the file without its trailing newline.
+func (p *FileProvider) Get(_ context.Context, path string) (string, error) {
+	if p.dir != "" {
+		dir := filepath.Clean(p.dir)
+		path = filepath.Join(dir, path)
+		// references cannot read files outside of the secrets directory
+		if path != dir && !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return "", fmt.Errorf("path is outside of the secrets directory")
+		}
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
@@ -0,0 +1,88 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Provider names used in secret references
+const (
+	ProviderEnv   = "env"
+	ProviderFile  = "file"
+	ProviderVault = "vault"
+)
+
+// referencePattern matches references such as ${secret:env:DB_PASSWORD}
+var referencePattern = regexp.MustCompile(`\$\{secret:([^:}]+):([^}]+)\}`)
+
+// Provider returns the value of a secret stored under a path.
+type Provider interface {
+	Get(ctx context.Context, path string) (string, error)
+}
+
+// Resolver replaces secret references with the values of their provider.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver returns a Resolver reading secrets from the providers by their name.
+func NewResolver(providers map[string]Provider) *Resolver {
+	return &Resolver{providers: providers}
+}
+
+// HasReference returns whether the value contains a secret reference.
+func HasReference(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// Resolve returns the value with each of its secret references replaced by the secret.
+// Errors only mention the reference, never the value of a secret.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	resolved, _, err := r.ResolveWithSecrets(ctx, value)
+	return resolved, err
+}
+
+// ResolveWithSecrets is Resolve also returning the secrets the value references,
+// so they can be masked wherever the resolved value is printed.
+func (r *Resolver) ResolveWithSecrets(ctx context.Context, value string) (resolved string, secrets []string, err error) {
+	matches := referencePattern.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return value, nil, nil
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		name, path := value[m[2]:m[3]], value[m[4]:m[5]]
+		provider, ok := r.providers[name]
+		if !ok {
+			return "", nil, fmt.Errorf("unknown secret provider \"%s\" in \"%s\"", name, value[m[0]:m[1]])
+		}
+		secret, err := provider.Get(ctx, path)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to resolve \"%s\": %w", value[m[0]:m[1]], err)
+		}
+		b.WriteString(value[last:m[0]])
+		b.WriteString(secret)
+		secrets = append(secrets, secret)
+		last = m[1]
+	}
+	b.WriteString(value[last:])
+
+	return b.String(), secrets, nil
+}
+
+// EnvReference returns the reference to the environment variable named after the parts,
+// such as ${secret:env:POSTGRES_CONNECTION_URL} for "postgres" and "connection_url".
+func EnvReference(parts ...string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(strings.Join(parts, "_")))
+
+	return fmt.Sprintf("${secret:%s:%s}", ProviderEnv, name)
+}
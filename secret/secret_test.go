@@ -0,0 +1,145 @@
+package secret_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/meteor/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverResolve(t *testing.T) {
+	ctx := context.Background()
+	resolver := secret.NewResolver(map[string]secret.Provider{
+		secret.ProviderEnv: secret.EnvProvider{},
+	})
+
+	t.Run("should return value without reference as it is", func(t *testing.T) {
+		value, err := resolver.Resolve(ctx, "postgres://admin@localhost:5432")
+		assert.NoError(t, err)
+		assert.Equal(t, "postgres://admin@localhost:5432", value)
+	})
+
+	t.Run("should replace every reference of the value", func(t *testing.T) {
+		os.Setenv("TEST_DB_USER", "admin")
+		os.Setenv("TEST_DB_PASSWORD", "p@ss")
+		defer os.Unsetenv("TEST_DB_USER")
+		defer os.Unsetenv("TEST_DB_PASSWORD")
+
+		value, err := resolver.Resolve(ctx, "postgres://${secret:env:TEST_DB_USER}:${secret:env:TEST_DB_PASSWORD}@localhost:5432")
+		assert.NoError(t, err)
+		assert.Equal(t, "postgres://admin:p@ss@localhost:5432", value)
+	})
+
+	t.Run("should return the secrets the value references", func(t *testing.T) {
+		os.Setenv("TEST_DB_HOST", "db.internal")
+		defer os.Unsetenv("TEST_DB_HOST")
+
+		value, secrets, err := resolver.ResolveWithSecrets(ctx, "postgres://admin@${secret:env:TEST_DB_HOST}:5432")
+		assert.NoError(t, err)
+		assert.Equal(t, "postgres://admin@db.internal:5432", value)
+		assert.Equal(t, []string{"db.internal"}, secrets)
+	})
+
+	t.Run("should return error for unknown provider", func(t *testing.T) {
+		_, err := resolver.Resolve(ctx, "${secret:aws:db-password}")
+		assert.EqualError(t, err, "unknown secret provider \"aws\" in \"${secret:aws:db-password}\"")
+	})
+
+	t.Run("should return error for unset environment variable", func(t *testing.T) {
+		_, err := resolver.Resolve(ctx, "${secret:env:TEST_UNSET_PASSWORD}")
+		assert.EqualError(t, err, "failed to resolve \"${secret:env:TEST_UNSET_PASSWORD}\": environment variable \"TEST_UNSET_PASSWORD\" is not set")
+	})
+}
+
+func TestFileProvider(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "postgres"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "postgres", "password"), []byte("p@ss\n"), 0600))
+
+	t.Run("should read file relative to the directory without trailing newline", func(t *testing.T) {
+		value, err := secret.NewFileProvider(dir).Get(ctx, "postgres/password")
+		assert.NoError(t, err)
+		assert.Equal(t, "p@ss", value)
+	})
+
+	t.Run("should read path as it is without directory", func(t *testing.T) {
+		value, err := secret.NewFileProvider("").Get(ctx, filepath.Join(dir, "postgres", "password"))
+		assert.NoError(t, err)
+		assert.Equal(t, "p@ss", value)
+	})
+
+	t.Run("should return error for path outside of the directory", func(t *testing.T) {
+		_, err := secret.NewFileProvider(filepath.Join(dir, "postgres")).Get(ctx, "../../etc/passwd")
+		assert.EqualError(t, err, "path is outside of the secrets directory")
+	})
+}
+
+func TestVaultProvider(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/meteor/postgres":
+			w.Write([]byte(`{"data": {"data": {"password": "p@ss", "account": {"type": "service_account"}}, "metadata": {"version": 1}}}`))
+		case "/v1/kv/meteor/postgres":
+			w.Write([]byte(`{"data": {"password": "v1-p@ss"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := secret.NewVaultProvider(server.URL, "test-token", nil)
+
+	t.Run("should read key of a KV version 2 secret", func(t *testing.T) {
+		value, err := provider.Get(ctx, "secret/data/meteor/postgres#password")
+		assert.NoError(t, err)
+		assert.Equal(t, "p@ss", value)
+	})
+
+	t.Run("should read key of a KV version 1 secret", func(t *testing.T) {
+		value, err := provider.Get(ctx, "kv/meteor/postgres#password")
+		assert.NoError(t, err)
+		assert.Equal(t, "v1-p@ss", value)
+	})
+
+	t.Run("should return object value as json", func(t *testing.T) {
+		value, err := provider.Get(ctx, "secret/data/meteor/postgres#account")
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"type": "service_account"}`, value)
+	})
+
+	t.Run("should return error for path without key", func(t *testing.T) {
+		_, err := provider.Get(ctx, "secret/data/meteor/postgres")
+		assert.EqualError(t, err, "vault path \"secret/data/meteor/postgres\" has to be in the form <path>#<key>")
+	})
+
+	t.Run("should return error for missing key", func(t *testing.T) {
+		_, err := provider.Get(ctx, "secret/data/meteor/postgres#user")
+		assert.EqualError(t, err, "key \"user\" not found in vault secret \"secret/data/meteor/postgres\"")
+	})
+
+	t.Run("should return error for missing secret", func(t *testing.T) {
+		_, err := provider.Get(ctx, "secret/data/meteor/mysql#password")
+		assert.EqualError(t, err, "vault secret \"secret/data/meteor/mysql\" not found")
+	})
+
+	t.Run("should return error for rejected token", func(t *testing.T) {
+		_, err := secret.NewVaultProvider(server.URL, "wrong-token", nil).Get(ctx, "secret/data/meteor/postgres#password")
+		assert.EqualError(t, err, "failed to read vault secret \"secret/data/meteor/postgres\": vault returned status 403")
+	})
+}
+
+func TestEnvReference(t *testing.T) {
+	assert.Equal(t, "${secret:env:COMPASS_HEADERS_X_API_KEY}", secret.EnvReference("compass", "headers.X-Api-Key"))
+}
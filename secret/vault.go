@@ -0,0 +1,105 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// VaultProvider reads secrets from a HashiCorp Vault KV secrets engine over its HTTP API.
+// Paths are the API path of the secret and the key of the value, such as "secret/data/meteor/postgres#password"
+// for a KV version 2 engine mounted on "secret".
+type VaultProvider struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// NewVaultProvider returns a VaultProvider sending requests to the Vault server on address with the token.
+func NewVaultProvider(address, token string, client *http.Client) *VaultProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &VaultProvider{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+// Get returns the value of the key in the secret.
+// Values that are not strings, such as a service account stored as an object, are returned as JSON.
+func (p *VaultProvider) Get(ctx context.Context, path string) (string, error) {
+	secretPath, key, ok := splitVaultPath(path)
+	if !ok {
+		return "", fmt.Errorf("vault path \"%s\" has to be in the form <path>#<key>", path)
+	}
+
+	data, err := p.read(ctx, secretPath)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key \"%s\" not found in vault secret \"%s\"", key, secretPath)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode key \"%s\" of vault secret \"%s\": %w", key, secretPath, err)
+	}
+
+	return string(b), nil
+}
+
+func (p *VaultProvider) read(ctx context.Context, path string) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s", p.address, strings.TrimPrefix(path, "/")), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret \"%s\": %w", path, err)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("vault secret \"%s\" not found", path)
+	default:
+		return nil, fmt.Errorf("failed to read vault secret \"%s\": vault returned status %d", path, res.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode vault secret \"%s\": %w", path, err)
+	}
+
+	// KV version 2 nests the values of the secret under data, next to its metadata
+	if nested, ok := body.Data["data"].(map[string]interface{}); ok {
+		if _, ok := body.Data["metadata"]; ok {
+			return nested, nil
+		}
+	}
+
+	return body.Data, nil
+}
+
+func splitVaultPath(path string) (secretPath, key string, ok bool) {
+	i := strings.LastIndex(path, "#")
+	if i <= 0 || i == len(path)-1 {
+		return "", "", false
+	}
+
+	return path[:i], path[i+1:], true
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/odpf/meteor/scheduler"
+	"github.com/odpf/salt/log"
+	"github.com/pkg/errors"
+)
+
+// startTimeout is the max time a triggered run takes to start before the request fails.
+const startTimeout = 10 * time.Second
+
+// Scheduler lists and triggers the recipes, it is implemented by scheduler.Scheduler.
+type Scheduler interface {
+	Recipes() []scheduler.RecipeStatus
+	Trigger(name string) error
+}
+
+// Server is the HTTP/JSON API to list recipes, trigger their runs and inspect them.
+//
+//	GET  /v1/recipes                 loaded recipes
+//	POST /v1/recipes/{name}/runs     trigger a run of the recipe
+//	GET  /v1/runs?recipe={name}      last runs, optionally of a recipe
+//	GET  /v1/runs/{id}               a run, with its result once finished
+//	GET  /v1/runs/{id}/events        NDJSON stream of the run progress until it ends
+type Server struct {
+	scheduler Scheduler
+	tracker   *Tracker
+	logger    log.Logger
+}
+
+// Recipe is a loaded recipe.
+type Recipe struct {
+	Name       string     `json:"name"`
+	Source     string     `json:"source"`
+	Processors []string   `json:"processors"`
+	Sinks      []string   `json:"sinks"`
+	Schedule   string     `json:"schedule,omitempty"`
+	Scheduled  bool       `json:"scheduled"`
+	Running    bool       `json:"running"`
+	NextRun    *time.Time `json:"next_run,omitempty"`
+}
+
+// New returns a Server triggering runs with the scheduler and inspecting them with the tracker.
+// The tracker has to observe the agent the scheduler runs recipes with.
+func New(sched Scheduler, tracker *Tracker, logger log.Logger) *Server {
+	return &Server{
+		scheduler: sched,
+		tracker:   tracker,
+		logger:    logger,
+	}
+}
+
+// Handler returns the handler serving the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/recipes", s.listRecipes)
+	mux.HandleFunc("/v1/recipes/", s.triggerRun)
+	mux.HandleFunc("/v1/runs", s.listRuns)
+	mux.HandleFunc("/v1/runs/", s.getRun)
+	return mux
+}
+
+func (s *Server) listRecipes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	recipes := []Recipe{}
+	for _, status := range s.scheduler.Recipes() {
+		rcp := Recipe{
+			Name:       status.Recipe.Name,
+			Source:     status.Recipe.SourceNames(),
+			Processors: []string{},
+			Sinks:      []string{},
+			Schedule:   status.Recipe.Schedule,
+			Scheduled:  status.Scheduled,
+			Running:    status.Running,
+		}
+		for _, pr := range status.Recipe.Processors {
+			rcp.Processors = append(rcp.Processors, pr.Name)
+		}
+		for _, sr := range status.Recipe.Sinks {
+			rcp.Sinks = append(rcp.Sinks, sr.Name)
+		}
+		if !status.NextRun.IsZero() {
+			next := status.NextRun
+			rcp.NextRun = &next
+		}
+		recipes = append(recipes, rcp)
+	}
+
+	s.writeJSON(w, http.StatusOK, recipes)
+}
+
+// triggerRun handles POST /v1/recipes/{name}/runs
+func (s *Server) triggerRun(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/v1/recipes/")
+	if !strings.HasSuffix(name, "/runs") {
+		s.writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	name = strings.TrimSuffix(name, "/runs")
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	// waiting before triggering, a run failing right away could otherwise end before it is waited for
+	started, stopWaiting := s.tracker.NextRun(name)
+	defer stopWaiting()
+
+	if err := s.scheduler.Trigger(name); err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrRecipeNotFound):
+			s.writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, scheduler.ErrRecipeRunning):
+			s.writeError(w, http.StatusConflict, err)
+		default:
+			s.writeError(w, http.StatusServiceUnavailable, err)
+		}
+		return
+	}
+
+	var record RunRecord
+	ctx, cancel := context.WithTimeout(r.Context(), startTimeout)
+	defer cancel()
+	select {
+	case record = <-started:
+	case <-ctx.Done():
+		s.writeError(w, http.StatusGatewayTimeout, errors.Wrap(ctx.Err(), "run was triggered but did not start"))
+		return
+	}
+
+	w.Header().Set("Location", "/v1/runs/"+record.ID)
+	s.writeJSON(w, http.StatusAccepted, record)
+}
+
+func (s *Server) listRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, s.tracker.Runs(r.URL.Query().Get("recipe")))
+}
+
+// getRun handles GET /v1/runs/{id} and GET /v1/runs/{id}/events
+func (s *Server) getRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/v1/runs/")
+	if strings.HasSuffix(id, "/events") {
+		s.streamEvents(w, r, strings.TrimSuffix(id, "/events"))
+		return
+	}
+
+	record, ok := s.tracker.Run(id)
+	if !ok {
+		s.writeError(w, http.StatusNotFound, errors.New("run not found"))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, record)
+}
+
+// streamEvents writes the events of the run as NDJSON until the run ends, the last event is the ended one.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, id string) {
+	if _, ok := s.tracker.Run(id); !ok {
+		s.writeError(w, http.StatusNotFound, errors.New("run not found"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	write := func(event Event) bool {
+		if err := enc.Encode(event); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	// a finished run only gets the ended event
+	if events, unsubscribe, ok := s.tracker.Subscribe(id); ok {
+		defer unsubscribe()
+		record, _ := s.tracker.Run(id)
+		if !write(Event{Type: EventProgress, Time: time.Now(), Run: record}) {
+			return
+		}
+	loop:
+		for {
+			select {
+			case event, open := <-events:
+				if !open {
+					break loop
+				}
+				if !write(event) {
+					return
+				}
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+
+	record, _ := s.tracker.Run(id)
+	write(Event{Type: EventEnded, Time: time.Now(), Run: record})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Warn("failed to write response", "error", err)
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
+	s.writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,252 @@
+package server_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/models"
+	"github.com/odpf/meteor/recipe"
+	"github.com/odpf/meteor/scheduler"
+	"github.com/odpf/meteor/server"
+	"github.com/odpf/meteor/test/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRecipe = recipe.Recipe{
+	Name:     "test-recipe",
+	Schedule: "@hourly",
+	Source:   recipe.PluginRecipe{Name: "bigquery"},
+	Sinks:    []recipe.PluginRecipe{{Name: "compass"}},
+}
+
+func TestServer(t *testing.T) {
+	t.Run("should list loaded recipes", func(t *testing.T) {
+		srv := newTestServer(t, nil)
+
+		res, err := http.Get(srv.URL + "/v1/recipes")
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var recipes []server.Recipe
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&recipes))
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []server.Recipe{{
+			Name:       "test-recipe",
+			Source:     "bigquery",
+			Processors: []string{},
+			Sinks:      []string{"compass"},
+			Schedule:   "@hourly",
+			Scheduled:  true,
+		}}, recipes)
+	})
+
+	t.Run("should trigger a run and return its result", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := newTestServer(t, release)
+
+		res, err := http.Post(srv.URL+"/v1/recipes/test-recipe/runs", "application/json", nil)
+		require.NoError(t, err)
+		var started server.RunRecord
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&started))
+		res.Body.Close()
+		assert.Equal(t, http.StatusAccepted, res.StatusCode)
+		assert.Equal(t, "/v1/runs/"+started.ID, res.Header.Get("Location"))
+		assert.Equal(t, server.StatusRunning, started.Status)
+
+		res, err = http.Post(srv.URL+"/v1/recipes/test-recipe/runs", "application/json", nil)
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+		close(release)
+		srv.waitRuns()
+
+		res, err = http.Get(srv.URL + "/v1/runs/" + started.ID)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		var finished server.RunRecord
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&finished))
+		assert.Equal(t, server.StatusFailed, finished.Status)
+		assert.Equal(t, 2, finished.RecordCount)
+		assert.Equal(t, []agent.SinkRun{{Name: "compass", SuccessCount: 1, FailureCount: 1}}, finished.Sinks)
+		require.NotNil(t, finished.Result)
+		assert.Equal(t, []string{"failed to run extractor"}, finished.Result.Errors)
+	})
+
+	t.Run("should return a run failing right away", func(t *testing.T) {
+		srv := newTestServer(t, nil)
+		srv.scheduler.failFast = true
+
+		var ids []string
+		for i := 0; i < 2; i++ {
+			res, err := http.Post(srv.URL+"/v1/recipes/test-recipe/runs", "application/json", nil)
+			require.NoError(t, err)
+			var started server.RunRecord
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&started))
+			res.Body.Close()
+			assert.Equal(t, http.StatusAccepted, res.StatusCode)
+			ids = append(ids, started.ID)
+			srv.waitRuns()
+		}
+
+		runs := srv.scheduler.tracker.Runs(testRecipe.Name)
+		require.Len(t, runs, 2)
+		assert.Equal(t, []string{runs[1].ID, runs[0].ID}, ids)
+
+		run, ok := srv.scheduler.tracker.Run(ids[0])
+		require.True(t, ok)
+		assert.Equal(t, server.StatusFailed, run.Status)
+	})
+
+	t.Run("should return error when triggering an unknown recipe", func(t *testing.T) {
+		srv := newTestServer(t, nil)
+
+		res, err := http.Post(srv.URL+"/v1/recipes/unknown/runs", "application/json", nil)
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("should list runs of a recipe", func(t *testing.T) {
+		srv := newTestServer(t, nil)
+		for i := 0; i < 2; i++ {
+			res, err := http.Post(srv.URL+"/v1/recipes/test-recipe/runs", "application/json", nil)
+			require.NoError(t, err)
+			res.Body.Close()
+			srv.waitRuns()
+		}
+
+		res, err := http.Get(srv.URL + "/v1/runs?recipe=test-recipe")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		var runs []server.RunRecord
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&runs))
+		assert.Len(t, runs, 2)
+		assert.True(t, runs[0].StartedAt.After(runs[1].StartedAt))
+	})
+
+	t.Run("should stream events of a run until it ends", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := newTestServer(t, release)
+
+		res, err := http.Post(srv.URL+"/v1/recipes/test-recipe/runs", "application/json", nil)
+		require.NoError(t, err)
+		var started server.RunRecord
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&started))
+		res.Body.Close()
+
+		res, err = http.Get(srv.URL + "/v1/runs/" + started.ID + "/events")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+		close(release)
+
+		var events []server.Event
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			var event server.Event
+			require.NoError(t, json.NewDecoder(strings.NewReader(scanner.Text())).Decode(&event))
+			events = append(events, event)
+		}
+		require.NotEmpty(t, events)
+		assert.Equal(t, server.EventProgress, events[0].Type)
+		last := events[len(events)-1]
+		assert.Equal(t, server.EventEnded, last.Type)
+		assert.Equal(t, server.StatusFailed, last.Run.Status)
+	})
+}
+
+type testServer struct {
+	*httptest.Server
+	scheduler *fakeScheduler
+}
+
+func (s *testServer) waitRuns() {
+	s.scheduler.wg.Wait()
+}
+
+func newTestServer(t *testing.T, release chan struct{}) *testServer {
+	tracker := server.NewTracker(0)
+	sched := &fakeScheduler{tracker: tracker, release: release}
+	srv := httptest.NewServer(server.New(sched, tracker, utils.Logger).Handler())
+	t.Cleanup(srv.Close)
+	return &testServer{Server: srv, scheduler: sched}
+}
+
+// fakeScheduler runs the test recipe by sending the events of a run to the tracker
+type fakeScheduler struct {
+	tracker *server.Tracker
+	release chan struct{}
+	// failFast makes the runs fail before Trigger returns
+	failFast bool
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	running  bool
+}
+
+func (s *fakeScheduler) Recipes() []scheduler.RecipeStatus {
+	return []scheduler.RecipeStatus{{Recipe: testRecipe, Scheduled: true}}
+}
+
+func (s *fakeScheduler) Trigger(name string) error {
+	if name != testRecipe.Name {
+		return scheduler.ErrRecipeNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return scheduler.ErrRecipeRunning
+	}
+	s.running = true
+
+	s.wg.Add(1)
+	if s.failFast {
+		// the run ends before Trigger returns
+		s.mu.Unlock()
+		s.failRun()
+		s.mu.Lock()
+		return nil
+	}
+	go s.run()
+
+	return nil
+}
+
+func (s *fakeScheduler) run() {
+	s.tracker.OnRunStart(testRecipe)
+	if s.release != nil {
+		<-s.release
+	}
+	s.tracker.OnRecordExtracted(testRecipe.Name, models.Record{})
+	s.tracker.OnRecordExtracted(testRecipe.Name, models.Record{})
+	s.tracker.OnSinkBatch(testRecipe.Name, agent.SinkBatch{Sink: "compass", RecordCount: 1, Attempts: 1})
+	s.tracker.OnSinkBatch(testRecipe.Name, agent.SinkBatch{Sink: "compass", RecordCount: 1, Attempts: 3, Err: errors.New("sink error")})
+	s.end(agent.Run{
+		Recipe:      testRecipe,
+		RecordCount: 2,
+		Sinks:       []agent.SinkRun{{Name: "compass", SuccessCount: 1, FailureCount: 1}},
+		Error:       errors.New("failed to run extractor"),
+	})
+}
+
+func (s *fakeScheduler) failRun() {
+	s.tracker.OnRunStart(testRecipe)
+	s.end(agent.Run{Recipe: testRecipe, Error: errors.New("failed to connect")})
+}
+
+func (s *fakeScheduler) end(run agent.Run) {
+	defer s.wg.Done()
+	s.tracker.OnRunEnd(run)
+
+	s.mu.Lock()
+	s.running = false
+	s.mu.Unlock()
+}
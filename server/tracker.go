@@ -0,0 +1,308 @@
+package server
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/odpf/meteor/agent"
+	"github.com/odpf/meteor/models"
+	"github.com/odpf/meteor/recipe"
+	"github.com/odpf/meteor/report"
+)
+
+const defaultHistoryLimit = 100
+
+// Run statuses
+const (
+	StatusRunning = "running"
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// Event types, the first event of a stream is a progress event with the state of the run
+const (
+	EventProgress  = "progress"
+	EventSinkBatch = "sink_batch"
+	EventEnded     = "ended"
+)
+
+// RunRecord is a run of a recipe, in progress or finished.
+type RunRecord struct {
+	ID          string          `json:"id"`
+	Recipe      string          `json:"recipe"`
+	Status      string          `json:"status"`
+	StartedAt   time.Time       `json:"started_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	RecordCount int             `json:"record_count"`
+	Sinks       []agent.SinkRun `json:"sinks"`
+	// Result is the result of the finished run, with its error chain when it failed.
+	Result *report.Recipe `json:"result,omitempty"`
+}
+
+// Event is a change in the progress of a run.
+type Event struct {
+	Type  string      `json:"type"`
+	Time  time.Time   `json:"time"`
+	Run   RunRecord   `json:"run"`
+	Batch *BatchEvent `json:"batch,omitempty"`
+}
+
+// BatchEvent describes a batch a sink is done with.
+type BatchEvent struct {
+	Sink        string `json:"sink"`
+	RecordCount int    `json:"record_count"`
+	Attempts    int    `json:"attempts"`
+	LatencyInMs int64  `json:"latency_in_ms"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Tracker observes the runs of the agent to keep their progress and a history of the last runs.
+// Runs are tracked by recipe name, so a recipe must not run more than once at the same time.
+type Tracker struct {
+	agent.BaseObserver
+	limit int
+
+	mu      sync.Mutex
+	runs    []*trackedRun
+	active  map[string]*trackedRun
+	waiters map[string][]chan RunRecord
+}
+
+type trackedRun struct {
+	record      RunRecord
+	sinks       map[string]int
+	subscribers []chan Event
+}
+
+// NewTracker returns a Tracker keeping up to limit runs, 0 uses the default of 100.
+func NewTracker(limit int) *Tracker {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	return &Tracker{
+		limit:   limit,
+		active:  make(map[string]*trackedRun),
+		waiters: make(map[string][]chan RunRecord),
+	}
+}
+
+func (t *Tracker) OnRunStart(rcp recipe.Recipe) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tr := &trackedRun{
+		record: RunRecord{
+			ID:        strconv.FormatInt(time.Now().UnixNano(), 36),
+			Recipe:    rcp.Name,
+			Status:    StatusRunning,
+			StartedAt: time.Now(),
+		},
+		sinks: make(map[string]int),
+	}
+	for _, sr := range rcp.Sinks {
+		tr.record.Sinks = append(tr.record.Sinks, agent.SinkRun{Name: sr.Name})
+		tr.sinks[sr.Name] = len(tr.record.Sinks) - 1
+	}
+	t.active[rcp.Name] = tr
+	t.runs = append(t.runs, tr)
+	t.evict()
+
+	for _, w := range t.waiters[rcp.Name] {
+		w <- tr.snapshot()
+	}
+	delete(t.waiters, rcp.Name)
+}
+
+func (t *Tracker) OnRecordExtracted(recipeName string, _ models.Record) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tr, ok := t.active[recipeName]
+	if !ok {
+		return
+	}
+	tr.record.RecordCount++
+	tr.publish(Event{Type: EventProgress, Time: time.Now()})
+}
+
+func (t *Tracker) OnSinkBatch(recipeName string, batch agent.SinkBatch) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tr, ok := t.active[recipeName]
+	if !ok {
+		return
+	}
+	event := &BatchEvent{
+		Sink:        batch.Sink,
+		RecordCount: batch.RecordCount,
+		Attempts:    batch.Attempts,
+		LatencyInMs: batch.Latency.Milliseconds(),
+	}
+	if i, ok := tr.sinks[batch.Sink]; ok {
+		if batch.Err != nil {
+			tr.record.Sinks[i].FailureCount += batch.RecordCount
+		} else {
+			tr.record.Sinks[i].SuccessCount += batch.RecordCount
+		}
+	}
+	if batch.Err != nil {
+		event.Error = batch.Err.Error()
+	}
+	tr.publish(Event{Type: EventSinkBatch, Time: time.Now(), Batch: event})
+}
+
+func (t *Tracker) OnRunEnd(run agent.Run) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tr, ok := t.active[run.Recipe.Name]
+	if !ok {
+		return
+	}
+	delete(t.active, run.Recipe.Name)
+
+	now := time.Now()
+	result := report.New([]agent.Run{run}).Recipes[0]
+	tr.record.FinishedAt = &now
+	tr.record.Result = &result
+	tr.record.RecordCount = run.RecordCount
+	if run.Sinks != nil {
+		tr.record.Sinks = run.Sinks
+	}
+	tr.record.Status = StatusSuccess
+	if !run.Success {
+		tr.record.Status = StatusFailed
+	}
+
+	// subscribers get the ended event from the finished run once their channel is closed
+	for _, sub := range tr.subscribers {
+		close(sub)
+	}
+	tr.subscribers = nil
+}
+
+// Runs returns the tracked runs of the recipe, or of all recipes when it is empty, the latest first.
+func (t *Tracker) Runs(recipeName string) []RunRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	records := []RunRecord{}
+	for i := len(t.runs) - 1; i >= 0; i-- {
+		if recipeName == "" || t.runs[i].record.Recipe == recipeName {
+			records = append(records, t.runs[i].snapshot())
+		}
+	}
+
+	return records
+}
+
+// Run returns the tracked run with the id.
+func (t *Tracker) Run(id string) (RunRecord, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tr, ok := t.find(id)
+	if !ok {
+		return RunRecord{}, false
+	}
+	return tr.snapshot(), true
+}
+
+// Subscribe returns the events of a run in progress, the channel is closed once the run ends.
+// Events are dropped when the subscriber is too slow to receive them, so it does not block the run.
+// It returns false when the run is not found or already finished.
+func (t *Tracker) Subscribe(id string) (events <-chan Event, unsubscribe func(), ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tr, ok := t.find(id)
+	if !ok || tr.record.FinishedAt != nil {
+		return nil, nil, false
+	}
+	ch := make(chan Event, 64)
+	tr.subscribers = append(tr.subscribers, ch)
+
+	unsubscribe = func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for i, sub := range tr.subscribers {
+			if sub == ch {
+				tr.subscribers = append(tr.subscribers[:i], tr.subscribers[i+1:]...)
+				close(ch)
+				return
+			}
+		}
+	}
+
+	return ch, unsubscribe, true
+}
+
+// NextRun returns a channel getting the next run of the recipe once it is started. It has to be called
+// before triggering the run, so a run ending right away is not missed.
+// cancel stops waiting, it has to be called once the run is received or no longer expected.
+func (t *Tracker) NextRun(recipeName string) (started <-chan RunRecord, cancel func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ch := make(chan RunRecord, 1)
+	t.waiters[recipeName] = append(t.waiters[recipeName], ch)
+
+	cancel = func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		waiters := t.waiters[recipeName]
+		for i, w := range waiters {
+			if w == ch {
+				t.waiters[recipeName] = append(waiters[:i:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(t.waiters[recipeName]) == 0 {
+			delete(t.waiters, recipeName)
+		}
+	}
+
+	return ch, cancel
+}
+
+func (t *Tracker) find(id string) (*trackedRun, bool) {
+	for _, tr := range t.runs {
+		if tr.record.ID == id {
+			return tr, true
+		}
+	}
+	return nil, false
+}
+
+// evict removes the oldest finished runs above the limit.
+func (t *Tracker) evict() {
+	for i := 0; len(t.runs) > t.limit && i < len(t.runs); {
+		if t.runs[i].record.FinishedAt == nil {
+			i++
+			continue
+		}
+		t.runs = append(t.runs[:i], t.runs[i+1:]...)
+	}
+}
+
+// publish sends the event with the current state of the run to the subscribers without blocking.
+func (tr *trackedRun) publish(event Event) {
+	if len(tr.subscribers) == 0 {
+		return
+	}
+	event.Run = tr.snapshot()
+	for _, sub := range tr.subscribers {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
+
+func (tr *trackedRun) snapshot() RunRecord {
+	record := tr.record
+	record.Sinks = append([]agent.SinkRun(nil), tr.record.Sinks...)
+	return record
+}
@@ -0,0 +1,97 @@
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FileStore is a Store that keeps every value in a single JSON file.
+type FileStore struct {
+	path string
+	data map[string]map[string]string
+	mu   sync.Mutex
+}
+
+// NewFileStore loads the JSON file on path, the file is created on first write if it does not exist.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path: path,
+		data: make(map[string]map[string]string),
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read state file")
+	}
+	if len(bytes) == 0 {
+		return s, nil
+	}
+	if err = json.Unmarshal(bytes, &s.data); err != nil {
+		return nil, errors.Wrap(err, "failed to parse state file")
+	}
+
+	return s, nil
+}
+
+// Get returns the value of key in namespace.
+func (s *FileStore) Get(namespace, key string) (value string, exists bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, exists = s.data[namespace][key]
+	return
+}
+
+// Set saves the value of key in namespace and writes the whole state to the file.
+func (s *FileStore) Set(namespace, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data[namespace]; !ok {
+		s.data[namespace] = make(map[string]string)
+	}
+	s.data[namespace][key] = value
+
+	return s.write()
+}
+
+// Close is a no-op since every Set is already written to the file.
+func (s *FileStore) Close() error {
+	return nil
+}
+
+// write replaces the state file atomically so a crash never leaves a partial file behind.
+func (s *FileStore) write() error {
+	bytes, err := json.Marshal(s.data)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode state")
+	}
+
+	dir := filepath.Dir(s.path)
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create state directory")
+	}
+	tmp, err := ioutil.TempFile(dir, filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary state file")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(bytes); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to write state file")
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to write state file")
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,86 @@
+package state
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite" // used to register the sqlite driver
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS state (
+	namespace TEXT NOT NULL,
+	key       TEXT NOT NULL,
+	value     TEXT NOT NULL,
+	PRIMARY KEY (namespace, key)
+)`
+
+// SQLiteStore is a Store backed by a SQLite database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// sqlitePragmas make writers wait for the database to be unlocked, such as by another meteor process,
+// and let readers go on while it is written.
+const sqlitePragmas = "?_pragma=busy_timeout(5000)&_pragma=journal_mode(wal)"
+
+// OpenSQLite opens the SQLite database on path so it can be written by concurrent runs.
+// Its connections are limited to one, as SQLite only has a single writer at a time.
+func OpenSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path+sqlitePragmas)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open sqlite database")
+	}
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+// NewSQLiteStore opens the SQLite database on path and creates the state table if needed.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := OpenSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to create state table")
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// Get returns the value of key in namespace.
+func (s *SQLiteStore) Get(namespace, key string) (value string, exists bool, err error) {
+	err = s.db.QueryRow(
+		"SELECT value FROM state WHERE namespace = ? AND key = ?",
+		namespace, key,
+	).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to query state")
+	}
+
+	return value, true, nil
+}
+
+// Set saves the value of key in namespace.
+func (s *SQLiteStore) Set(namespace, key, value string) error {
+	_, err := s.db.Exec(
+		`INSERT INTO state (namespace, key, value) VALUES (?, ?, ?)
+		ON CONFLICT (namespace, key) DO UPDATE SET value = excluded.value`,
+		namespace, key, value,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to save state")
+	}
+
+	return nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
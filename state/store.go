@@ -0,0 +1,140 @@
+package state
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/odpf/meteor/plugins"
+)
+
+// Store types
+const (
+	TypeFile   = "file"
+	TypeSQLite = "sqlite"
+)
+
+// Store persists values between runs of the agent.
+// Values are grouped by namespace, usually one per recipe source.
+type Store interface {
+	Get(namespace, key string) (value string, exists bool, err error)
+	Set(namespace, key, value string) error
+	Close() error
+}
+
+// New returns a Store of the given type backed by the file on path.
+func New(storeType, path string) (Store, error) {
+	switch storeType {
+	case TypeFile:
+		return NewFileStore(path)
+	case TypeSQLite:
+		return NewSQLiteStore(path)
+	default:
+		return nil, fmt.Errorf("unknown state store type \"%s\"", storeType)
+	}
+}
+
+// Scope returns a plugins.State that reads and writes values under the given namespace.
+func Scope(store Store, namespace string) plugins.State {
+	return &scopedState{
+		store:     store,
+		namespace: namespace,
+	}
+}
+
+type scopedState struct {
+	store     Store
+	namespace string
+}
+
+func (s *scopedState) Get(key string) (string, bool, error) {
+	return s.store.Get(s.namespace, key)
+}
+
+func (s *scopedState) Set(key, value string) error {
+	return s.store.Set(s.namespace, key, value)
+}
+
+// ReadOnly returns a Store reading values from the given store and discarding every write,
+// so a run can use the values of previous runs without changing them.
+func ReadOnly(store Store) Store {
+	return &readOnlyStore{store: store}
+}
+
+type readOnlyStore struct {
+	store Store
+}
+
+func (s *readOnlyStore) Get(namespace, key string) (string, bool, error) {
+	return s.store.Get(namespace, key)
+}
+
+func (s *readOnlyStore) Set(namespace, key, value string) error {
+	return nil
+}
+
+// Close does not close the underlying store, it is owned by the caller of ReadOnly.
+func (s *readOnlyStore) Close() error {
+	return nil
+}
+
+// StagedStore is a Store keeping its writes in memory until they are committed,
+// so values such as watermarks are only saved once the records of a run reached the sinks.
+type StagedStore struct {
+	store  Store
+	mu     sync.Mutex
+	staged map[string]map[string]string
+}
+
+// Staged returns a StagedStore reading values from the given store and staging every write.
+func Staged(store Store) *StagedStore {
+	return &StagedStore{
+		store:  store,
+		staged: make(map[string]map[string]string),
+	}
+}
+
+// Get returns the staged value of key in namespace, or the one of the underlying store when none is staged.
+func (s *StagedStore) Get(namespace, key string) (string, bool, error) {
+	s.mu.Lock()
+	value, ok := s.staged[namespace][key]
+	s.mu.Unlock()
+	if ok {
+		return value, true, nil
+	}
+
+	return s.store.Get(namespace, key)
+}
+
+// Set stages the value of key in namespace.
+func (s *StagedStore) Set(namespace, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.staged[namespace] == nil {
+		s.staged[namespace] = make(map[string]string)
+	}
+	s.staged[namespace][key] = value
+
+	return nil
+}
+
+// Commit saves the staged values to the underlying store.
+func (s *StagedStore) Commit() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for namespace, values := range s.staged {
+		for key, value := range values {
+			if err := s.store.Set(namespace, key, value); err != nil {
+				return err
+			}
+			delete(values, key)
+		}
+		delete(s.staged, namespace)
+	}
+
+	return nil
+}
+
+// Close does not close the underlying store, it is owned by the caller of Staged.
+func (s *StagedStore) Close() error {
+	return nil
+}
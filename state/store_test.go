@@ -0,0 +1,162 @@
+package state_test
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/odpf/meteor/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("should return error for unknown store type", func(t *testing.T) {
+		_, err := state.New("redis", "")
+		assert.EqualError(t, err, "unknown state store type \"redis\"")
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	t.Run("should return not exists for missing key", func(t *testing.T) {
+		store, err := state.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, exists, err := store.Get("recipe/bigquery", "last_modified")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("should persist values between stores on the same path", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		store, err := state.NewFileStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Set("recipe/bigquery", "last_modified", "2021-12-01T00:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		reopened, err := state.NewFileStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, exists, err := reopened.Get("recipe/bigquery", "last_modified")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "2021-12-01T00:00:00Z", value)
+	})
+}
+
+func TestSQLiteStore(t *testing.T) {
+	t.Run("should save values set concurrently by stores on the same path", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.db")
+		var stores []*state.SQLiteStore
+		for i := 0; i < 2; i++ {
+			store, err := state.NewSQLiteStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+			stores = append(stores, store)
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 200)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(store *state.SQLiteStore, namespace string) {
+				defer wg.Done()
+				for j := 0; j < 25; j++ {
+					errs <- store.Set(namespace, strconv.Itoa(j), "value")
+				}
+			}(stores[i%2], "recipe-"+strconv.Itoa(i))
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+		value, exists, err := stores[0].Get("recipe-7", "24")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "value", value)
+	})
+}
+
+func TestScope(t *testing.T) {
+	t.Run("should isolate values of different namespaces", func(t *testing.T) {
+		store, err := state.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		first := state.Scope(store, "recipe-1/tableau")
+		second := state.Scope(store, "recipe-2/tableau")
+
+		if err := first.Set("last_modified", "first"); err != nil {
+			t.Fatal(err)
+		}
+
+		value, exists, err := first.Get("last_modified")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "first", value)
+
+		_, exists, err = second.Get("last_modified")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestReadOnly(t *testing.T) {
+	t.Run("should read values but discard writes", func(t *testing.T) {
+		store, err := state.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Set("recipe/tableau", "last_modified", "before"); err != nil {
+			t.Fatal(err)
+		}
+
+		readOnly := state.ReadOnly(store)
+		assert.NoError(t, readOnly.Set("recipe/tableau", "last_modified", "after"))
+
+		value, exists, err := readOnly.Get("recipe/tableau", "last_modified")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "before", value)
+	})
+}
+
+func TestStaged(t *testing.T) {
+	t.Run("should only save staged values once committed", func(t *testing.T) {
+		store, err := state.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Set("recipe/tableau", "last_modified", "before"); err != nil {
+			t.Fatal(err)
+		}
+
+		staged := state.Staged(store)
+		assert.NoError(t, staged.Set("recipe/tableau", "last_modified", "after"))
+
+		value, _, err := staged.Get("recipe/tableau", "last_modified")
+		assert.NoError(t, err)
+		assert.Equal(t, "after", value)
+		value, _, err = store.Get("recipe/tableau", "last_modified")
+		assert.NoError(t, err)
+		assert.Equal(t, "before", value)
+
+		assert.NoError(t, staged.Commit())
+		value, _, err = store.Get("recipe/tableau", "last_modified")
+		assert.NoError(t, err)
+		assert.Equal(t, "after", value)
+	})
+}
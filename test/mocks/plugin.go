@@ -12,11 +12,24 @@ type Plugin struct {
 	mock.Mock
 }
 
+// Info returns an empty info unless an Info call is expected, as the agent reads the config schema of its plugins.
 func (m *Plugin) Info() plugins.Info {
+	if !m.expects("Info") {
+		return plugins.Info{}
+	}
 	args := m.Called()
 	return args.Get(0).(plugins.Info)
 }
 
+func (m *Plugin) expects(method string) bool {
+	for _, call := range m.ExpectedCalls {
+		if call.Method == method {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Plugin) Validate(config map[string]interface{}) error {
 	args := m.Called(config)
 	return args.Error(0)
@@ -105,3 +118,21 @@ func (m *Emitter) GetAllData() (data []models.Metadata) {
 
 	return
 }
+
+type State struct {
+	data map[string]string
+}
+
+func NewState() *State {
+	return &State{data: make(map[string]string)}
+}
+
+func (m *State) Get(key string) (string, bool, error) {
+	value, exists := m.data[key]
+	return value, exists, nil
+}
+
+func (m *State) Set(key string, value string) error {
+	m.data[key] = value
+	return nil
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+const serviceName = "meteor"
+
+// Exporters of the spans.
+const (
+	// ExporterOTLP sends spans to an OpenTelemetry collector over gRPC.
+	ExporterOTLP = "otlp"
+	// ExporterFile writes spans as JSON to a file, to debug runs offline.
+	ExporterFile = "file"
+)
+
+// Config contains the configuration of the spans exporter.
+type Config struct {
+	Exporter string
+	// OTLPEndpoint is the address of the collector used by the otlp exporter.
+	OTLPEndpoint string
+	// OTLPInsecure disables TLS of the otlp exporter.
+	OTLPInsecure bool
+	// FilePath is the file the file exporter writes to.
+	FilePath string
+}
+
+// NewProvider returns a TracerProvider sending spans to the exporter of the config.
+// The returned close function flushes the remaining spans and closes the exporter.
+func NewProvider(ctx context.Context, cfg Config) (tp *sdktrace.TracerProvider, closeFn func(context.Context) error, err error) {
+	var (
+		exporter  sdktrace.SpanExporter
+		closeFile = func() error { return nil }
+	)
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if exporter, err = otlptracegrpc.New(ctx, opts...); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create otlp exporter")
+		}
+	case ExporterFile:
+		f, err := os.Create(cfg.FilePath)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create traces file")
+		}
+		if exporter, err = stdouttrace.New(stdouttrace.WithWriter(f)); err != nil {
+			f.Close()
+			return nil, nil, errors.Wrap(err, "failed to create file exporter")
+		}
+		closeFile = f.Close
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter \"%s\"", cfg.Exporter)
+	}
+
+	tp = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	closeFn = func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if cerr := closeFile(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+
+	return tp, closeFn, nil
+}
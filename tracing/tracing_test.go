@@ -0,0 +1,36 @@
+package tracing_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/meteor/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should write spans as json to the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traces.json")
+		tp, closeFn, err := tracing.NewProvider(ctx, tracing.Config{Exporter: tracing.ExporterFile, FilePath: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, span := tp.Tracer("test").Start(ctx, "test-span")
+		span.End()
+		assert.NoError(t, closeFn(ctx))
+
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"Name":"test-span"`)
+	})
+
+	t.Run("should return error for unknown exporter", func(t *testing.T) {
+		_, _, err := tracing.NewProvider(ctx, tracing.Config{Exporter: "jaeger"})
+		assert.EqualError(t, err, "unknown tracing exporter \"jaeger\"")
+	})
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/odpf/meteor/plugins"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigSchema returns the JSON Schema of a config struct, built from its mapstructure, validate, default and sensitive tags.
+// Fields are described by the comment above them in the sample config of the plugin.
+func ConfigSchema(config interface{}, sampleConfig string) *plugins.Schema {
+	schema := typeSchema(reflect.TypeOf(config))
+	describe(schema, sampleConfigNode(sampleConfig))
+
+	return schema
+}
+
+func typeSchema(t reflect.Type) *plugins.Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Map:
+		return &plugins.Schema{Type: plugins.SchemaTypeObject, AdditionalProperties: typeSchema(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		return &plugins.Schema{Type: plugins.SchemaTypeArray, Items: typeSchema(t.Elem())}
+	case reflect.String:
+		return &plugins.Schema{Type: plugins.SchemaTypeString}
+	case reflect.Bool:
+		return &plugins.Schema{Type: plugins.SchemaTypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &plugins.Schema{Type: plugins.SchemaTypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &plugins.Schema{Type: plugins.SchemaTypeNumber}
+	default:
+		return &plugins.Schema{}
+	}
+}
+
+func structSchema(t reflect.Type) *plugins.Schema {
+	schema := &plugins.Schema{
+		Type:       plugins.SchemaTypeObject,
+		Properties: make(map[string]*plugins.Schema),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tagParts := strings.Split(field.Tag.Get("mapstructure"), ",")
+		name := tagParts[0]
+		if name == "-" {
+			continue
+		}
+		// squashed fields are decoded from the keys of the parent
+		if hasTagOption(tagParts[1:], "squash") {
+			embedded := typeSchema(field.Type)
+			for prop, propSchema := range embedded.Properties {
+				schema.Properties[prop] = propSchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := typeSchema(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			switch {
+			case rule == "required":
+				schema.Required = append(schema.Required, name)
+			case strings.HasPrefix(rule, "oneof="):
+				for _, value := range strings.Fields(strings.TrimPrefix(rule, "oneof=")) {
+					fieldSchema.Enum = append(fieldSchema.Enum, scalarValue(fieldSchema.Type, value))
+				}
+			}
+		}
+		if value, ok := field.Tag.Lookup("default"); ok {
+			fieldSchema.Default = scalarValue(fieldSchema.Type, value)
+		}
+		if sensitive, err := strconv.ParseBool(field.Tag.Get("sensitive")); err == nil {
+			fieldSchema.Sensitive = sensitive
+		}
+		schema.Properties[name] = fieldSchema
+	}
+
+	return schema
+}
+
+// scalarValue converts a value of a struct tag to the type of the field, values that do not convert are kept as is.
+func scalarValue(schemaType, value string) interface{} {
+	switch schemaType {
+	case plugins.SchemaTypeInteger:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case plugins.SchemaTypeNumber:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case plugins.SchemaTypeBoolean:
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	}
+	return value
+}
+
+// sampleConfigNode returns the mapping node of the sample config, or nil when it cannot be parsed.
+func sampleConfigNode(sampleConfig string) *yaml.Node {
+	// sample configs are indented with tabs, which yaml does not allow
+	var lines []string
+	for _, line := range strings.Split(sampleConfig, "\n") {
+		trimmed := strings.TrimLeft(line, "\t")
+		lines = append(lines, strings.Repeat("  ", len(line)-len(trimmed))+trimmed)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	if node := doc.Content[0]; node.Kind == yaml.MappingNode {
+		return node
+	}
+	return nil
+}
+
+// describe sets the description of each property to the comment above its key in the sample config.
+func describe(schema *plugins.Schema, node *yaml.Node) {
+	if schema == nil || node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		prop, ok := schema.Properties[keyNode.Value]
+		if !ok {
+			continue
+		}
+		if comment := commentText(keyNode.HeadComment); comment != "" {
+			prop.Description = comment
+		}
+		describe(prop, valueNode)
+	}
+}
+
+func commentText(comment string) string {
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		if line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+func hasTagOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/odpf/meteor/plugins"
+	"github.com/pkg/errors"
+)
+
+// GetStateTime reads a time watermark saved by SetStateTime.
+// It returns zero time if state is nil or the key has never been saved.
+func GetStateTime(state plugins.State, key string) (t time.Time, err error) {
+	if state == nil {
+		return
+	}
+
+	value, exists, err := state.Get(key)
+	if err != nil || !exists {
+		return
+	}
+	t, err = time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		err = errors.Wrapf(err, "invalid time in state \"%s\"", key)
+	}
+
+	return
+}
+
+// SetStateTime saves a time watermark to state, it does nothing if state is nil or t is zero.
+func SetStateTime(state plugins.State, key string, t time.Time) error {
+	if state == nil || t.IsZero() {
+		return nil
+	}
+
+	return state.Set(key, t.UTC().Format(time.RFC3339Nano))
+}